@@ -0,0 +1,152 @@
+package ectologger
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBufferSize is the number of messages a BufferScope holds by default.
+const defaultBufferSize = 100
+
+// bufferScopeKey is the context key of the BufferScope attached by NewBufferScope.
+type bufferScopeKey struct{}
+
+// BufferOptions configures a BufferScope.
+type BufferOptions struct {
+	// Size is the number of messages held. When full, the oldest message is discarded. Defaults to 100.
+	Size int
+	// BufferBelow is the level below which messages are held. Messages at this level or above
+	// that do not trigger a flush are logged straight away. Defaults to WarnLevel.
+	BufferBelow string
+	// Trigger reports whether a message flushes the buffer. Defaults to messages at ErrorLevel and above.
+	Trigger func(msg EctoLogMessage) bool
+}
+
+// BufferScope holds the low level messages of a unit of work, such as an HTTP request, in a
+// ring buffer. The held messages are discarded when the scope ends, unless a message matching
+// the trigger is logged first, in which case the buffered history is written before it.
+type BufferScope struct {
+	next EctoLogFunc
+	opts BufferOptions
+
+	mu        sync.Mutex
+	ring      []EctoLogMessage
+	start     int // Index of the oldest message in ring
+	count     int // Number of messages in ring
+	triggered bool
+	ended     bool
+}
+
+// NewBufferScope returns a BufferScope writing to next and a copy of ctx carrying it.
+// Messages logged with that context through BufferScopeLogFunc are routed into the scope.
+func NewBufferScope(ctx context.Context, next EctoLogFunc, opts BufferOptions) (context.Context, *BufferScope) {
+	if opts.Size <= 0 {
+		opts.Size = defaultBufferSize
+	}
+	if opts.BufferBelow == "" {
+		opts.BufferBelow = WarnLevel
+	}
+	if opts.Trigger == nil {
+		opts.Trigger = func(msg EctoLogMessage) bool {
+			return LevelEnabled(msg.Level, ErrorLevel)
+		}
+	}
+	s := &BufferScope{next: next, opts: opts, ring: make([]EctoLogMessage, opts.Size)}
+	return context.WithValue(ctx, bufferScopeKey{}, s), s
+}
+
+// BufferScopeFromContext returns the BufferScope attached to ctx, or nil if there is none.
+func BufferScopeFromContext(ctx context.Context) *BufferScope {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(bufferScopeKey{}).(*BufferScope)
+	return s
+}
+
+// BufferScopeLogFunc returns a log function that routes messages whose context carries a
+// BufferScope into that scope, and passes every other message to next.
+func BufferScopeLogFunc(next EctoLogFunc) EctoLogFunc {
+	return func(msg EctoLogMessage) {
+		if s := BufferScopeFromContext(msg.Ctx); s != nil {
+			s.Log(msg)
+			return
+		}
+		next(msg)
+	}
+}
+
+// Logger returns a Logger that logs through the scope.
+func (s *BufferScope) Logger() Logger {
+	return NewEctoLogger(s.Log)
+}
+
+// Log holds msg if it is below the buffered level, flushes the buffer first if msg is a trigger,
+// and otherwise writes it straight away. After a trigger, or once the scope ended, every
+// message is written straight away.
+func (s *BufferScope) Log(msg EctoLogMessage) {
+	s.mu.Lock()
+	if s.triggered || s.ended {
+		s.mu.Unlock()
+		s.next(msg)
+		return
+	}
+
+	if s.opts.Trigger(msg) {
+		s.triggered = true
+		history := s.drain()
+		s.mu.Unlock()
+		for _, held := range history {
+			s.next(held)
+		}
+		s.next(msg)
+		return
+	}
+
+	if LevelEnabled(msg.Level, s.opts.BufferBelow) {
+		s.mu.Unlock()
+		s.next(msg)
+		return
+	}
+
+	s.ring[(s.start+s.count)%len(s.ring)] = msg
+	if s.count < len(s.ring) {
+		s.count++
+	} else {
+		s.start = (s.start + 1) % len(s.ring)
+	}
+	s.mu.Unlock()
+}
+
+// Flush writes the buffered messages without ending the scope, as if a trigger had been logged:
+// messages logged after Flush are written straight away too.
+func (s *BufferScope) Flush() {
+	s.mu.Lock()
+	s.triggered = true
+	history := s.drain()
+	s.mu.Unlock()
+
+	for _, held := range history {
+		s.next(held)
+	}
+}
+
+// End discards the buffered messages. Messages logged after End are written straight away.
+func (s *BufferScope) End() {
+	s.mu.Lock()
+	s.drain()
+	s.ended = true
+	s.mu.Unlock()
+}
+
+// drain empties the ring, returning the held messages oldest first. s.mu must be held.
+func (s *BufferScope) drain() []EctoLogMessage {
+	history := make([]EctoLogMessage, 0, s.count)
+	for i := 0; i < s.count; i++ {
+		idx := (s.start + i) % len(s.ring)
+		history = append(history, s.ring[idx])
+		s.ring[idx] = EctoLogMessage{}
+	}
+	s.start, s.count = 0, 0
+	return history
+}
@@ -0,0 +1,85 @@
+package ectologger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferScopeDiscardsWithoutTrigger(t *testing.T) {
+	rec := &messageRecorder{}
+	ctx, scope := NewBufferScope(context.Background(), rec.Log, BufferOptions{})
+	logger := NewEctoLogger(BufferScopeLogFunc(rec.Log))
+
+	logger.DebugContext(ctx, "debug")
+	logger.InfoContext(ctx, "info")
+	logger.WarnContext(ctx, "warn")
+	logger.Info("outside scope")
+	scope.End()
+
+	assert.Equal(t, []string{"warn", "outside scope"}, rec.Messages())
+}
+
+func TestBufferScopeFlushesOnError(t *testing.T) {
+	rec := &messageRecorder{}
+	ctx, scope := NewBufferScope(context.Background(), rec.Log, BufferOptions{})
+	defer scope.End()
+	logger := NewEctoLogger(BufferScopeLogFunc(rec.Log)).WithContext(ctx)
+
+	logger.Debug("first")
+	logger.Info("second")
+	logger.Error("failed")
+	logger.Debug("after")
+
+	assert.Equal(t, []string{"first", "second", "failed", "after"}, rec.Messages())
+}
+
+func TestBufferScopeRingOverwritesOldest(t *testing.T) {
+	rec := &messageRecorder{}
+	_, scope := NewBufferScope(context.Background(), rec.Log, BufferOptions{Size: 2})
+	logger := scope.Logger()
+
+	logger.Debug("1")
+	logger.Debug("2")
+	logger.Debug("3")
+	scope.Flush()
+
+	assert.Equal(t, []string{"2", "3"}, rec.Messages())
+}
+
+func TestBufferScopeFlushActsAsTrigger(t *testing.T) {
+	rec := &messageRecorder{}
+	_, scope := NewBufferScope(context.Background(), rec.Log, BufferOptions{})
+	logger := scope.Logger()
+
+	logger.Debug("before")
+	scope.Flush()
+	logger.Debug("after")
+	scope.End()
+
+	assert.Equal(t, []string{"before", "after"}, rec.Messages())
+}
+
+func TestBufferScopeCustomTrigger(t *testing.T) {
+	rec := &messageRecorder{}
+	_, scope := NewBufferScope(context.Background(), rec.Log, BufferOptions{
+		BufferBelow: ErrorLevel,
+		Trigger: func(msg EctoLogMessage) bool {
+			return msg.Fields["slow"] == true
+		},
+	})
+	logger := scope.Logger()
+
+	logger.Warn("held")
+	logger.WithField("slow", true).Info("slow request")
+
+	assert.Equal(t, []string{"held", "slow request"}, rec.Messages())
+}
+
+func TestBufferScopeFromContext(t *testing.T) {
+	assert.Nil(t, BufferScopeFromContext(context.Background()))
+
+	ctx, scope := NewBufferScope(context.Background(), func(EctoLogMessage) {}, BufferOptions{})
+	assert.Same(t, scope, BufferScopeFromContext(ctx))
+}
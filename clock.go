@@ -0,0 +1,38 @@
+package ectologger
+
+import (
+	"time"
+)
+
+// Clock tells the time. Loggers stamp each message with the time of their Clock, and wrappers
+// such as Sampler and RateLimiter measure their intervals with one, so tests can control time.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock reading the system time. It is the default everywhere a Clock is used.
+var SystemClock Clock = systemClock{}
+
+// systemClock implements SystemClock.
+type systemClock struct{}
+
+// Now returns time.Now().
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithClock sets the Clock the logger and its sub loggers stamp messages with. The default is SystemClock.
+func WithClock(clock Clock) Option {
+	return func(l *EctoLogger) {
+		l.clock = clock
+	}
+}
+
+// messageTime returns the time msg was logged at, or the current time for messages built
+// without a logger that have none.
+func messageTime(msg EctoLogMessage) time.Time {
+	if msg.Time.IsZero() {
+		return time.Now()
+	}
+	return msg.Time
+}
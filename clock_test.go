@@ -0,0 +1,70 @@
+package ectologger
+
+import (
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithClock(t *testing.T) {
+	rec := &messageRecorder{}
+	clock := newManualClock()
+	logger := NewEctoLogger(rec.Log, WithClock(clock))
+
+	logger.Info("root")
+	clock.Advance(time.Minute)
+	logger.WithField("key", "value").Warnf("sub %d", 1)
+
+	require.Len(t, rec.messages, 2)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), rec.messages[0].Time)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC), rec.messages[1].Time)
+}
+
+func TestTimeCapturedAtCallSite(t *testing.T) {
+	clock := newManualClock()
+	var held []EctoLogMessage
+	logger := NewEctoLogger(func(msg EctoLogMessage) { held = append(held, msg) }, WithClock(clock))
+
+	logger.Info("queued")
+	clock.Advance(time.Hour) // An async sink writes the message later
+
+	var logOutput string
+	log.SetOutput(writerFunc(func(p []byte) (int, error) {
+		logOutput = string(p)
+		return len(p), nil
+	}))
+	log.SetFlags(0)
+	defer log.SetFlags(log.LstdFlags)
+
+	LogfmtEctoLogFunc(held[0])
+	assert.True(t, strings.HasPrefix(logOutput, "time=2024-01-01T00:00:00Z "), logOutput)
+}
+
+func TestWrapperSummariesUseClock(t *testing.T) {
+	rec := &messageRecorder{}
+	clock := newManualClock()
+	sampler := NewSampler(rec.Log, SamplerOptions{First: 1, Clock: clock})
+
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "repeated"})
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "repeated"})
+	clock.Advance(time.Minute)
+	sampler.Flush()
+
+	summaries := rec.messages
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "sampled out log messages", summaries[1].Message)
+	assert.Equal(t, clock.Now(), summaries[1].Time)
+}
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	assert.False(t, SystemClock.Now().Before(before))
+
+	rec := &messageRecorder{}
+	NewEctoLogger(rec.Log, WithClock(nil)).Info("nil clock falls back to the system clock")
+	assert.False(t, rec.messages[0].Time.Before(before))
+}
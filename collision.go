@@ -0,0 +1,149 @@
+package ectologger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CollisionPolicy decides what happens when a field is added with a key that already
+// exists in the same group of a logger.
+type CollisionPolicy int
+
+const (
+	// CollisionOverwrite replaces the existing value with the new one. This is the default.
+	CollisionOverwrite CollisionPolicy = iota
+	// CollisionKeepFirst keeps the existing value and discards the new one.
+	CollisionKeepFirst
+	// CollisionSuffix keeps both values, adding the new one under the key with a numeric suffix (key_1, key_2, ...).
+	CollisionSuffix
+	// CollisionReport keeps the existing value and reports ErrDuplicateField to the error handler.
+	CollisionReport
+)
+
+// String returns the name of the policy.
+func (p CollisionPolicy) String() string {
+	switch p {
+	case CollisionOverwrite:
+		return "overwrite"
+	case CollisionKeepFirst:
+		return "keep_first"
+	case CollisionSuffix:
+		return "suffix"
+	case CollisionReport:
+		return "report"
+	default:
+		return "CollisionPolicy(" + strconv.Itoa(int(p)) + ")"
+	}
+}
+
+// groupStart returns the index of the first typed field in the innermost open group.
+// When no group is open every typed field belongs to the root.
+func (l *ectoSubLogger) groupStart() int {
+	for i := len(l.typedFields) - 1; i >= 0; i-- {
+		if l.typedFields[i].Type == NamespaceType {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// lookup reports whether key is already used in the innermost open group, either by a root map
+// field or by the index of a typed field (-1 when there is none).
+func (l *ectoSubLogger) lookup(key string) (inMap bool, index int) {
+	start := l.groupStart()
+	if len(l.groups) == 0 {
+		_, inMap = l.fields[key]
+	}
+	for i := len(l.typedFields) - 1; i >= start; i-- {
+		if l.typedFields[i].Key == key && l.typedFields[i].Type != SkipType {
+			return inMap, i
+		}
+	}
+	return inMap, -1
+}
+
+// addField adds f to the innermost open group, applying the collision policy.
+// Fields added through the map API stay in the map while no group is open so Fields
+// keeps carrying them; everything else is kept in order as a typed field.
+func (l *ectoSubLogger) addField(f Field, value interface{}, fromMap bool) {
+	switch f.Type {
+	case SkipType:
+		return
+	case NamespaceType:
+		l.typedFields = append(l.typedFields, f)
+		l.groups = append(l.groups, f.Key)
+		return
+	}
+
+	inMap, index := l.lookup(f.Key)
+	if inMap || index >= 0 {
+		switch l.collisions {
+		case CollisionKeepFirst:
+			return
+		case CollisionReport:
+			reportError(fmt.Errorf("%w: %q", ErrDuplicateField, l.path(f.Key)))
+			return
+		case CollisionSuffix:
+			f.Key = l.uniqueKey(f.Key)
+		default:
+			if inMap {
+				l.fields = copyFields(l.fields, 0)
+				delete(l.fields, f.Key)
+			}
+			if index >= 0 {
+				l.typedFields = append(l.typedFields[:index:index], l.typedFields[index+1:]...)
+			}
+		}
+	}
+
+	if fromMap && len(l.groups) == 0 {
+		l.fields[f.Key] = value
+		return
+	}
+	l.typedFields = append(l.typedFields, f)
+}
+
+// addMap adds every entry of fields, in key order so suffixes and group order are deterministic.
+func (l *ectoSubLogger) addMap(fields map[string]interface{}) {
+	// Copy the map before writing to it, it may be shared with a parent logger or the caller
+	l.fields = copyFields(l.fields, len(fields))
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		l.addField(Any(k, fields[k]), fields[k], true)
+	}
+}
+
+// uniqueKey returns key with the lowest numeric suffix that is not used in the innermost open group.
+func (l *ectoSubLogger) uniqueKey(key string) string {
+	for i := 1; ; i++ {
+		candidate := key + "_" + strconv.Itoa(i)
+		if inMap, index := l.lookup(candidate); !inMap && index < 0 {
+			return candidate
+		}
+	}
+}
+
+// path returns key prefixed with the names of the open groups, joined by dots.
+func (l *ectoSubLogger) path(key string) string {
+	if len(l.groups) == 0 {
+		return key
+	}
+	return strings.Join(l.groups, ".") + "." + key
+}
+
+// copyFields returns a copy of fields with room for extra more entries.
+func copyFields(fields map[string]interface{}, extra int) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields)+extra)
+	for k, v := range fields {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package ectologger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithGroupNestsSubsequentFields(t *testing.T) {
+	var capturedMsg EctoLogMessage
+	logger := NewEctoLogger(func(msg EctoLogMessage) {
+		capturedMsg = msg
+	})
+
+	logger.WithField("id", "root").
+		WithGroup("db").
+		WithField("id", "db").
+		WithTypedFields(Int64("rows", 3)).
+		Info("test message")
+
+	assert.Equal(t, map[string]interface{}{"id": "root"}, capturedMsg.Fields)
+	assert.Equal(t, map[string]interface{}{
+		"id": "root",
+		"db": map[string]interface{}{"id": "db", "rows": int64(3)},
+	}, capturedMsg.FieldMap())
+}
+
+func TestCollisionPolicies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   CollisionPolicy
+		expected map[string]interface{}
+		reported bool
+	}{
+		{"Overwrite", CollisionOverwrite, map[string]interface{}{"id": "second"}, false},
+		{"KeepFirst", CollisionKeepFirst, map[string]interface{}{"id": "first"}, false},
+		{"Suffix", CollisionSuffix, map[string]interface{}{"id": "first", "id_1": "second"}, false},
+		{"Report", CollisionReport, map[string]interface{}{"id": "first"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var reported error
+			SetErrorHandler(func(err error) { reported = err })
+			defer SetErrorHandler(nil)
+
+			var capturedMsg EctoLogMessage
+			logger := NewEctoLogger(func(msg EctoLogMessage) {
+				capturedMsg = msg
+			}, WithCollisionPolicy(tc.policy))
+
+			logger.WithField("id", "first").WithTypedFields(String("id", "second")).Info("test message")
+
+			assert.Equal(t, tc.expected, capturedMsg.FieldMap())
+			if tc.reported {
+				require.Error(t, reported)
+				assert.True(t, errors.Is(reported, ErrDuplicateField))
+			} else {
+				assert.NoError(t, reported)
+			}
+		})
+	}
+}
+
+func TestCollisionPolicyInsideGroup(t *testing.T) {
+	var reported error
+	SetErrorHandler(func(err error) { reported = err })
+	defer SetErrorHandler(nil)
+
+	var capturedMsg EctoLogMessage
+	logger := NewEctoLogger(func(msg EctoLogMessage) {
+		capturedMsg = msg
+	}, WithCollisionPolicy(CollisionReport))
+
+	logger.WithField("id", "root").
+		WithGroup("http").
+		WithField("id", "first").
+		WithField("id", "second").
+		Info("test message")
+
+	assert.Equal(t, map[string]interface{}{
+		"id":   "root",
+		"http": map[string]interface{}{"id": "first"},
+	}, capturedMsg.FieldMap())
+	assert.EqualError(t, reported, `ectologger: duplicate field: "http.id"`)
+}
+
+func TestWithFieldsDoesNotMutateCallerMap(t *testing.T) {
+	fields := map[string]interface{}{"key": "value"}
+	logger := NewEctoLogger(func(msg EctoLogMessage) {})
+
+	logger.WithFields(fields).WithField("other", "value")
+
+	assert.Equal(t, map[string]interface{}{"key": "value"}, fields)
+}
@@ -0,0 +1,612 @@
+package ectologger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of Config.Format and SinkConfig.Format.
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+// Sink types of SinkConfig.Type.
+const (
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+)
+
+// ErrInvalidConfig is wrapped by the errors returned for invalid configuration.
+var ErrInvalidConfig = errors.New("ectologger: invalid config")
+
+// ConfigError reports an invalid configuration value. Key is the dotted path of the offending
+// key, e.g. "sinks.1.path", or the environment variable it was read from. It is empty for
+// syntax errors, which belong to no key.
+type ConfigError struct {
+	Key     string
+	Message string
+}
+
+// Error returns the key and what is wrong with its value.
+func (e *ConfigError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%v: %s", ErrInvalidConfig, e.Message)
+	}
+	return fmt.Sprintf("%v: %s: %s", ErrInvalidConfig, e.Key, e.Message)
+}
+
+// Unwrap returns ErrInvalidConfig.
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// Config describes a logging pipeline, so every service can configure logging the same way.
+// It can be loaded from JSON, YAML and ECTOLOG_* environment variables and passed to NewFromConfig.
+type Config struct {
+	// Level is the minimum level logged. Defaults to info.
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+	// Format is the encoding of the sinks, json or logfmt. Defaults to json.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Sinks are the outputs messages are written to. Defaults to a single stdout sink.
+	Sinks []SinkConfig `json:"sinks,omitempty" yaml:"sinks,omitempty"`
+	// Sampling limits how often identical messages are logged. Disabled when nil.
+	Sampling *SamplingConfig `json:"sampling,omitempty" yaml:"sampling,omitempty"`
+	// Redact are the keys of the fields whose values are replaced with RedactedValue.
+	Redact []string `json:"redact,omitempty" yaml:"redact,omitempty"`
+	// Fields are added to every message, e.g. the service name and version.
+	Fields map[string]interface{} `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// SinkConfig describes an output of the pipeline.
+type SinkConfig struct {
+	// Type is stdout, stderr or file.
+	Type string `json:"type" yaml:"type"`
+	// Path is the file appended to by file sinks.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Format overrides Config.Format for this sink.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Level is the minimum level written to this sink, on top of Config.Level.
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+}
+
+// SamplingConfig configures a Sampler, see SamplerOptions.
+type SamplingConfig struct {
+	Interval          ConfigDuration `json:"interval,omitempty" yaml:"interval,omitempty"`
+	First             int            `json:"first,omitempty" yaml:"first,omitempty"`
+	Thereafter        int            `json:"thereafter,omitempty" yaml:"thereafter,omitempty"`
+	Probability       float64        `json:"probability,omitempty" yaml:"probability,omitempty"`
+	NeverSampleErrors bool           `json:"never_sample_errors,omitempty" yaml:"never_sample_errors,omitempty"`
+}
+
+// ConfigDuration is a time.Duration written as a string such as "1s" in configuration files.
+type ConfigDuration time.Duration
+
+// MarshalText formats the duration like time.Duration.String.
+func (d ConfigDuration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// String formats the duration like time.Duration.String.
+func (d ConfigDuration) String() string {
+	return time.Duration(d).String()
+}
+
+// UnmarshalText parses a duration such as "500ms" or "1m".
+func (d *ConfigDuration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = ConfigDuration(parsed)
+	return nil
+}
+
+// ParseConfigJSON parses a JSON configuration. Unknown keys are rejected so typos do not go unnoticed.
+// Errors are ConfigErrors naming the offending key.
+func ParseConfigJSON(data []byte) (Config, error) {
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			// The decoder does not tell the key of unknown fields and invalid durations
+			if keyErr := jsonConfigError(data, configType, ""); keyErr != nil {
+				return Config{}, keyErr
+			}
+		}
+		return Config{}, &ConfigError{Message: err.Error()}
+	}
+	return cfg, nil
+}
+
+// ParseConfigYAML parses a YAML configuration. Unknown keys are rejected so typos do not go unnoticed.
+// Errors are ConfigErrors naming the offending key.
+func ParseConfigYAML(data []byte) (Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		var node yaml.Node
+		if yaml.Unmarshal(data, &node) == nil {
+			if keyErr := yamlConfigError(&node, configType, ""); keyErr != nil {
+				return Config{}, keyErr
+			}
+		}
+		return Config{}, &ConfigError{Message: err.Error()}
+	}
+	return cfg, nil
+}
+
+// configType is the type configuration files are decoded into.
+var configType = reflect.TypeOf(Config{})
+
+// jsonConfigError decodes data into a value of type t key by key, returning a ConfigError for
+// the first key that cannot be decoded. path is the dotted path of data.
+func jsonConfigError(data json.RawMessage, t reflect.Type, path string) error {
+	switch t.Kind() {
+	case reflect.Pointer:
+		if string(bytes.TrimSpace(data)) == "null" {
+			return nil
+		}
+		return jsonConfigError(data, t.Elem(), path)
+	case reflect.Struct:
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return configValueError(path, t, err)
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field, ok := configField(t, "json", key)
+			if !ok {
+				return &ConfigError{Key: joinConfigKey(path, key), Message: "unknown key"}
+			}
+			if err := jsonConfigError(values[key], field.Type, joinConfigKey(path, key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return configValueError(path, t, err)
+		}
+		for i, item := range items {
+			if err := jsonConfigError(item, t.Elem(), joinConfigKey(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if err := json.Unmarshal(data, reflect.New(t).Interface()); err != nil {
+			return configValueError(path, t, err)
+		}
+		return nil
+	}
+}
+
+// yamlConfigError decodes node into a value of type t key by key, returning a ConfigError for
+// the first key that cannot be decoded. path is the dotted path of node.
+func yamlConfigError(node *yaml.Node, t reflect.Type, path string) error {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch {
+	case t.Kind() == reflect.Pointer:
+		if node.Tag == "!!null" {
+			return nil
+		}
+		return yamlConfigError(node, t.Elem(), path)
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			field, ok := configField(t, "yaml", key)
+			if !ok {
+				return &ConfigError{Key: joinConfigKey(path, key), Message: fmt.Sprintf("line %d: unknown key", node.Content[i].Line)}
+			}
+			if err := yamlConfigError(node.Content[i+1], field.Type, joinConfigKey(path, key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := yamlConfigError(item, t.Elem(), joinConfigKey(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if err := node.Decode(reflect.New(t).Interface()); err != nil {
+			var typeErr *yaml.TypeError
+			if errors.As(err, &typeErr) {
+				return &ConfigError{Key: path, Message: strings.Join(typeErr.Errors, "; ")}
+			}
+			return &ConfigError{Key: path, Message: fmt.Sprintf("line %d: %v", node.Line, err)}
+		}
+		return nil
+	}
+}
+
+// configField returns the field of the struct type t that the key is decoded into, matching
+// the names of the tag the way the decoder does.
+func configField(t reflect.Type, tag, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == key || tag == "json" && strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// configValueError returns a ConfigError for a value at path that cannot be decoded into type t.
+func configValueError(path string, t reflect.Type, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &ConfigError{Key: path, Message: fmt.Sprintf("cannot use %s as %s", typeErr.Value, t)}
+	}
+	return &ConfigError{Key: path, Message: err.Error()}
+}
+
+// joinConfigKey returns the dotted path of key below path.
+func joinConfigKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// LoadConfigFile reads a configuration file, parsed as YAML if its extension is .yaml or .yml
+// and as JSON otherwise.
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return parseConfigFile(path, data)
+}
+
+// parseConfigFile parses the contents of the config file at path according to its extension.
+func parseConfigFile(path string, data []byte) (Config, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseConfigYAML(data)
+	default:
+		return ParseConfigJSON(data)
+	}
+}
+
+// ConfigFromEnv returns the configuration described by the ECTOLOG_* environment variables, see ApplyEnv.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	err := cfg.ApplyEnv()
+	return cfg, err
+}
+
+// ApplyEnv overrides the configuration with the ECTOLOG_* environment variables that are set,
+// so a deployment can adjust a configuration file:
+//
+//	ECTOLOG_LEVEL                        level
+//	ECTOLOG_FORMAT                       format
+//	ECTOLOG_SINKS                        comma separated sinks: stdout, stderr or file:<path>
+//	ECTOLOG_REDACT                       comma separated keys to redact
+//	ECTOLOG_FIELDS                       comma separated key=value static fields, added to the configured ones
+//	ECTOLOG_SAMPLING_INTERVAL            sampling.interval
+//	ECTOLOG_SAMPLING_FIRST               sampling.first
+//	ECTOLOG_SAMPLING_THEREAFTER          sampling.thereafter
+//	ECTOLOG_SAMPLING_PROBABILITY         sampling.probability
+//	ECTOLOG_SAMPLING_NEVER_SAMPLE_ERRORS sampling.never_sample_errors
+//
+// Errors name the offending variable.
+func (c *Config) ApplyEnv() error {
+	if v, ok := os.LookupEnv("ECTOLOG_LEVEL"); ok {
+		c.Level = v
+	}
+	if v, ok := os.LookupEnv("ECTOLOG_FORMAT"); ok {
+		c.Format = v
+	}
+	if v, ok := os.LookupEnv("ECTOLOG_SINKS"); ok {
+		c.Sinks = nil
+		for _, s := range splitList(v) {
+			sink := SinkConfig{Type: s}
+			if path, ok := strings.CutPrefix(s, SinkFile+":"); ok {
+				sink = SinkConfig{Type: SinkFile, Path: path}
+			}
+			c.Sinks = append(c.Sinks, sink)
+		}
+	}
+	if v, ok := os.LookupEnv("ECTOLOG_REDACT"); ok {
+		c.Redact = splitList(v)
+	}
+	if v, ok := os.LookupEnv("ECTOLOG_FIELDS"); ok {
+		for _, pair := range splitList(v) {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return &ConfigError{Key: "ECTOLOG_FIELDS", Message: fmt.Sprintf("%q is not a key=value pair", pair)}
+			}
+			if c.Fields == nil {
+				c.Fields = map[string]interface{}{}
+			}
+			c.Fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return c.applySamplingEnv()
+}
+
+// applySamplingEnv applies the ECTOLOG_SAMPLING_* variables, enabling sampling if any is set.
+func (c *Config) applySamplingEnv() error {
+	sampling := SamplingConfig{}
+	if c.Sampling != nil {
+		sampling = *c.Sampling
+	}
+	set := false
+
+	if v, ok := os.LookupEnv("ECTOLOG_SAMPLING_INTERVAL"); ok {
+		if err := sampling.Interval.UnmarshalText([]byte(v)); err != nil {
+			return &ConfigError{Key: "ECTOLOG_SAMPLING_INTERVAL", Message: err.Error()}
+		}
+		set = true
+	}
+	for name, target := range map[string]*int{
+		"ECTOLOG_SAMPLING_FIRST":      &sampling.First,
+		"ECTOLOG_SAMPLING_THEREAFTER": &sampling.Thereafter,
+	} {
+		if v, ok := os.LookupEnv(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return &ConfigError{Key: name, Message: fmt.Sprintf("%q is not an integer", v)}
+			}
+			*target = n
+			set = true
+		}
+	}
+	if v, ok := os.LookupEnv("ECTOLOG_SAMPLING_PROBABILITY"); ok {
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return &ConfigError{Key: "ECTOLOG_SAMPLING_PROBABILITY", Message: fmt.Sprintf("%q is not a number", v)}
+		}
+		sampling.Probability = p
+		set = true
+	}
+	if v, ok := os.LookupEnv("ECTOLOG_SAMPLING_NEVER_SAMPLE_ERRORS"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return &ConfigError{Key: "ECTOLOG_SAMPLING_NEVER_SAMPLE_ERRORS", Message: fmt.Sprintf("%q is not a boolean", v)}
+		}
+		sampling.NeverSampleErrors = b
+		set = true
+	}
+
+	if set {
+		c.Sampling = &sampling
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate checks the configuration, returning a ConfigError for each invalid key.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, &ConfigError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Level != "" && !validLevel(c.Level) {
+		invalid("level", "unknown level %q", c.Level)
+	}
+	if c.Format != "" && !validFormat(c.Format) {
+		invalid("format", "unknown format %q, want json or logfmt", c.Format)
+	}
+	for i, sink := range c.Sinks {
+		key := fmt.Sprintf("sinks.%d", i)
+		switch sink.Type {
+		case SinkStdout, SinkStderr:
+			if sink.Path != "" {
+				invalid(key+".path", "only file sinks have a path")
+			}
+		case SinkFile:
+			if sink.Path == "" {
+				invalid(key+".path", "required for file sinks")
+			}
+		default:
+			invalid(key+".type", "unknown sink type %q, want stdout, stderr or file", sink.Type)
+		}
+		if sink.Format != "" && !validFormat(sink.Format) {
+			invalid(key+".format", "unknown format %q, want json or logfmt", sink.Format)
+		}
+		if sink.Level != "" && !validLevel(sink.Level) {
+			invalid(key+".level", "unknown level %q", sink.Level)
+		}
+	}
+	if s := c.Sampling; s != nil {
+		if s.Interval < 0 {
+			invalid("sampling.interval", "must not be negative")
+		}
+		if s.First < 0 {
+			invalid("sampling.first", "must not be negative")
+		}
+		if s.Thereafter < 0 {
+			invalid("sampling.thereafter", "must not be negative")
+		}
+		if s.Probability < 0 || s.Probability > 1 {
+			invalid("sampling.probability", "must be between 0 and 1")
+		}
+	}
+	for i, key := range c.Redact {
+		if key == "" {
+			invalid(fmt.Sprintf("redact.%d", i), "must not be empty")
+		}
+	}
+	if _, ok := c.Fields[""]; ok {
+		invalid("fields", "keys must not be empty")
+	}
+	return errors.Join(errs...)
+}
+
+// validFormat reports whether format is a known encoding.
+func validFormat(format string) bool {
+	return format == FormatJSON || format == FormatLogfmt
+}
+
+// NewFromConfig validates cfg and returns a Logger writing through the pipeline it describes.
+// The options configure the logger as for NewEctoLogger; their processors run before the
+// configured ones. File sinks stay open for the lifetime of the process.
+func NewFromConfig(cfg Config, opts ...Option) (Logger, error) {
+	p, err := buildPipeline(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewEctoLogger(p.logFunc, opts...), nil
+}
+
+// pipeline is the log function built from a Config and the resources it holds.
+type pipeline struct {
+	logFunc EctoLogFunc
+	sampler *Sampler
+	files   []*os.File
+}
+
+// close closes the sampler and the files of the pipeline.
+func (p *pipeline) close() error {
+	if p.sampler != nil {
+		p.sampler.Close()
+	}
+	var errs []error
+	for _, f := range p.files {
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// buildPipeline validates cfg and builds its log function, opening file sinks.
+func buildPipeline(cfg Config) (*pipeline, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Level == "" {
+		cfg.Level = InfoLevel
+	}
+	if cfg.Format == "" {
+		cfg.Format = FormatJSON
+	}
+	if len(cfg.Sinks) == 0 {
+		cfg.Sinks = []SinkConfig{{Type: SinkStdout}}
+	}
+
+	p := &pipeline{}
+	sinks := make([]EctoLogFunc, 0, len(cfg.Sinks))
+	for i, sc := range cfg.Sinks {
+		var w io.Writer
+		switch sc.Type {
+		case SinkStdout:
+			w = os.Stdout
+		case SinkStderr:
+			w = os.Stderr
+		case SinkFile:
+			f, err := os.OpenFile(sc.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+			if err != nil {
+				_ = p.close()
+				return nil, &ConfigError{Key: fmt.Sprintf("sinks.%d.path", i), Message: err.Error()}
+			}
+			p.files = append(p.files, f)
+			w = f
+		}
+
+		format := sc.Format
+		if format == "" {
+			format = cfg.Format
+		}
+		sink := NewJSONLogFunc(w)
+		if format == FormatLogfmt {
+			sink = NewLogfmtLogFunc(w)
+		}
+		if sc.Level != "" {
+			sink = Chain(sink, MinLevel(sc.Level))
+		}
+		sinks = append(sinks, sink)
+	}
+
+	logFunc := func(msg EctoLogMessage) {
+		// Resolve once for all sinks
+		msg = msg.Resolve()
+		for _, sink := range sinks {
+			sink(msg)
+		}
+	}
+
+	// Add fields and redact only after sampling, so the LogValuers of dropped messages never run
+	var processors []Processor
+	if len(cfg.Fields) > 0 {
+		processors = append(processors, AddFields(staticFields(cfg.Fields)...))
+	}
+	if len(cfg.Redact) > 0 {
+		processors = append(processors, Redact(cfg.Redact...))
+	}
+	logFunc = Chain(logFunc, processors...)
+
+	if s := cfg.Sampling; s != nil {
+		p.sampler = NewSampler(logFunc, SamplerOptions{
+			Interval:          time.Duration(s.Interval),
+			First:             s.First,
+			Thereafter:        s.Thereafter,
+			Probability:       s.Probability,
+			NeverSampleErrors: s.NeverSampleErrors,
+		})
+		logFunc = p.sampler.Log
+	}
+
+	p.logFunc = Chain(logFunc, MinLevel(cfg.Level))
+	return p, nil
+}
+
+// staticFields converts the configured fields to typed fields in key order.
+func staticFields(fields map[string]interface{}) []Field {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	typed := make([]Field, len(keys))
+	for i, k := range keys {
+		typed[i] = Any(k, fields[k])
+	}
+	return typed
+}
@@ -0,0 +1,269 @@
+package ectologger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{
+	Level:  DebugLevel,
+	Format: FormatLogfmt,
+	Sinks: []SinkConfig{
+		{Type: SinkStdout},
+		{Type: SinkFile, Path: "/var/log/app.log", Format: FormatJSON, Level: ErrorLevel},
+	},
+	Sampling: &SamplingConfig{Interval: ConfigDuration(time.Second), First: 10, Thereafter: 100},
+	Redact:   []string{"password"},
+	Fields:   map[string]interface{}{"service": "billing"},
+}
+
+func TestParseConfigJSON(t *testing.T) {
+	cfg, err := ParseConfigJSON([]byte(`{
+		"level": "debug",
+		"format": "logfmt",
+		"sinks": [{"type": "stdout"}, {"type": "file", "path": "/var/log/app.log", "format": "json", "level": "error"}],
+		"sampling": {"interval": "1s", "first": 10, "thereafter": 100},
+		"redact": ["password"],
+		"fields": {"service": "billing"}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, testConfig, cfg)
+}
+
+func TestParseConfigYAML(t *testing.T) {
+	cfg, err := ParseConfigYAML([]byte(`
+level: debug
+format: logfmt
+sinks:
+  - type: stdout
+  - type: file
+    path: /var/log/app.log
+    format: json
+    level: error
+sampling:
+  interval: 1s
+  first: 10
+  thereafter: 100
+redact: [password]
+fields:
+  service: billing
+`))
+	require.NoError(t, err)
+	assert.Equal(t, testConfig, cfg)
+
+	cfg, err = ParseConfigYAML(nil)
+	require.NoError(t, err)
+	assert.Equal(t, Config{}, cfg)
+}
+
+func TestParseConfigErrorsNameTheKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) (Config, error)
+		data  string
+		key   string
+	}{
+		{"json unknown key", ParseConfigJSON, `{"levle": "debug"}`, "levle"},
+		{"json unknown nested key", ParseConfigJSON, `{"sinks": [{"type": "stdout"}, {"type": "file", "pth": "app.log"}]}`, "sinks.1.pth"},
+		{"json wrong type", ParseConfigJSON, `{"sampling": {"first": "ten"}}`, "sampling.first"},
+		{"json invalid duration", ParseConfigJSON, `{"sampling": {"interval": "soon"}}`, "sampling.interval"},
+		{"json syntax", ParseConfigJSON, `{"level": }`, ""},
+		{"yaml unknown key", ParseConfigYAML, "levle: debug\n", "levle"},
+		{"yaml unknown nested key", ParseConfigYAML, "sinks:\n  - type: stdout\n    pth: x\n", "sinks.0.pth"},
+		{"yaml wrong type", ParseConfigYAML, "sampling:\n  first: ten\n", "sampling.first"},
+		{"yaml invalid duration", ParseConfigYAML, "sampling:\n  interval: soon\n", "sampling.interval"},
+		{"yaml syntax", ParseConfigYAML, "level: [debug\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse([]byte(tt.data))
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+			var cfgErr *ConfigError
+			require.ErrorAs(t, err, &cfgErr)
+			assert.Equal(t, tt.key, cfgErr.Key)
+			assert.NotEmpty(t, cfgErr.Message)
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "logging.yaml")
+	jsonPath := filepath.Join(dir, "logging.json")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("level: warn\n"), 0o600))
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"level": "error"}`), 0o600))
+
+	cfg, err := LoadConfigFile(yamlPath)
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, cfg.Level)
+
+	cfg, err = LoadConfigFile(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, ErrorLevel, cfg.Level)
+
+	_, err = LoadConfigFile(filepath.Join(dir, "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("ECTOLOG_LEVEL", "warn")
+	t.Setenv("ECTOLOG_FORMAT", "logfmt")
+	t.Setenv("ECTOLOG_SINKS", "stderr, file:/var/log/app.log")
+	t.Setenv("ECTOLOG_REDACT", "password,token")
+	t.Setenv("ECTOLOG_FIELDS", "service=billing,region=eu")
+	t.Setenv("ECTOLOG_SAMPLING_FIRST", "5")
+	t.Setenv("ECTOLOG_SAMPLING_INTERVAL", "2s")
+
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Level:    WarnLevel,
+		Format:   FormatLogfmt,
+		Sinks:    []SinkConfig{{Type: SinkStderr}, {Type: SinkFile, Path: "/var/log/app.log"}},
+		Sampling: &SamplingConfig{Interval: ConfigDuration(2 * time.Second), First: 5},
+		Redact:   []string{"password", "token"},
+		Fields:   map[string]interface{}{"service": "billing", "region": "eu"},
+	}, cfg)
+}
+
+func TestApplyEnvOverridesFile(t *testing.T) {
+	t.Setenv("ECTOLOG_LEVEL", "error")
+	t.Setenv("ECTOLOG_FIELDS", "region=eu")
+
+	cfg := Config{Level: DebugLevel, Format: FormatLogfmt, Fields: map[string]interface{}{"service": "billing"}}
+	require.NoError(t, cfg.ApplyEnv())
+	assert.Equal(t, ErrorLevel, cfg.Level)
+	assert.Equal(t, FormatLogfmt, cfg.Format)
+	assert.Equal(t, map[string]interface{}{"service": "billing", "region": "eu"}, cfg.Fields)
+}
+
+func TestApplyEnvErrorsNameTheVariable(t *testing.T) {
+	t.Setenv("ECTOLOG_SAMPLING_THEREAFTER", "often")
+
+	_, err := ConfigFromEnv()
+	var cfgErr *ConfigError
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, "ECTOLOG_SAMPLING_THEREAFTER", cfgErr.Key)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, testConfig.Validate())
+
+	err := Config{
+		Level: "verbose",
+		Sinks: []SinkConfig{
+			{Type: SinkStdout},
+			{Type: SinkFile},
+			{Type: "syslog", Level: "loud"},
+		},
+		Sampling: &SamplingConfig{Probability: 2},
+		Redact:   []string{""},
+	}.Validate()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	var keys []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var cfgErr *ConfigError
+		require.True(t, errors.As(e, &cfgErr))
+		keys = append(keys, cfgErr.Key)
+	}
+	assert.Equal(t, []string{"level", "sinks.1.path", "sinks.2.type", "sinks.2.level", "sampling.probability", "redact.0"}, keys)
+	assert.Contains(t, err.Error(), `ectologger: invalid config: level: unknown level "verbose"`)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	allPath := filepath.Join(dir, "all.log")
+	errorsPath := filepath.Join(dir, "errors.log")
+
+	p, err := buildPipeline(Config{
+		Level:  InfoLevel,
+		Format: FormatLogfmt,
+		Sinks: []SinkConfig{
+			{Type: SinkFile, Path: allPath},
+			{Type: SinkFile, Path: errorsPath, Format: FormatJSON, Level: ErrorLevel},
+		},
+		Redact: []string{"password"},
+		Fields: map[string]interface{}{"service": "billing"},
+	})
+	require.NoError(t, err)
+	logger := NewEctoLogger(p.logFunc, WithClock(newManualClock()))
+
+	logger.Debug("dropped")
+	logger.WithField("password", "secret").Info("login")
+	logger.Error("failed")
+	require.NoError(t, p.close())
+
+	all, err := os.ReadFile(allPath)
+	require.NoError(t, err)
+	assert.Equal(t, "time=2024-01-01T00:00:00Z level=info message=login password=[REDACTED] service=billing\n"+
+		"time=2024-01-01T00:00:00Z level=error message=failed service=billing\n", string(all))
+
+	errorLog, err := os.ReadFile(errorsPath)
+	require.NoError(t, err)
+	assert.Equal(t, `{"level":"error","message":"failed","service":"billing","time":"2024-01-01T00:00:00Z"}`+"\n", string(errorLog))
+}
+
+func TestNewFromConfigSampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	p, err := buildPipeline(Config{
+		Sinks:    []SinkConfig{{Type: SinkFile, Path: path}},
+		Sampling: &SamplingConfig{Interval: ConfigDuration(time.Hour), First: 2},
+	})
+	require.NoError(t, err)
+	logger := NewEctoLogger(p.logFunc)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("repeated")
+	}
+	require.NoError(t, p.close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[2], `"sampled_out":3`)
+}
+
+func TestNewFromConfigResolvesOnlySampledMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	p, err := buildPipeline(Config{
+		Sinks:    []SinkConfig{{Type: SinkFile, Path: path}},
+		Sampling: &SamplingConfig{Interval: ConfigDuration(time.Hour), First: 1},
+		Redact:   []string{"password"},
+	})
+	require.NoError(t, err)
+	valuer := &countingValuer{}
+	logger := NewEctoLogger(p.logFunc).WithField("lazy", valuer)
+
+	for i := 0; i < 10; i++ {
+		logger.Info("repeated")
+	}
+	assert.Equal(t, 1, valuer.calls)
+	require.NoError(t, p.close())
+	assert.Len(t, readLines(t, path), 2)
+}
+
+func TestNewFromConfigInvalid(t *testing.T) {
+	_, err := NewFromConfig(Config{Format: "xml"})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = NewFromConfig(Config{Sinks: []SinkConfig{{Type: SinkFile, Path: filepath.Join(t.TempDir(), "missing", "app.log")}}})
+	var cfgErr *ConfigError
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, "sinks.0.path", cfgErr.Key)
+
+	logger, err := NewFromConfig(Config{})
+	require.NoError(t, err)
+	assert.NotNil(t, logger)
+}
@@ -0,0 +1,22 @@
+package ectologger
+
+import (
+	"context"
+)
+
+// loggerKey is the context key of the Logger attached by NewContext.
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, e.g. a request scoped logger created by middleware.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the Logger attached to ctx with NewContext, and whether there was one.
+func FromContext(ctx context.Context) (Logger, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	logger, ok := ctx.Value(loggerKey{}).(Logger)
+	return logger, ok
+}
@@ -0,0 +1,18 @@
+package ectologger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextLogger(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	logger := NewDefaultEctoLogger().WithField("request_id", "123")
+	got, ok := FromContext(NewContext(context.Background(), logger))
+	assert.True(t, ok)
+	assert.Same(t, logger, got)
+}
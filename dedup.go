@@ -0,0 +1,238 @@
+package ectologger
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDedupMaxEntries bounds the number of fingerprints a Deduplicator remembers.
+const defaultDedupMaxEntries = 1000
+
+// DedupOptions configures a Deduplicator.
+type DedupOptions struct {
+	// Window is how long after its last occurrence a message is still considered a repeat. Defaults to one second.
+	Window time.Duration
+	// Fields are the keys of the fields that, next to the level, message and error text, make messages identical.
+	Fields []string
+	// MaxEntries bounds the number of fingerprints kept in memory. The least recently seen
+	// fingerprint is evicted, and its summary written, when the limit is reached. Defaults to 1000.
+	MaxEntries int
+	// Clock tells the current time. Defaults to SystemClock.
+	Clock Clock
+}
+
+// Deduplicator is a log function wrapper that collapses identical messages. The first
+// occurrence is logged straight away, repeats within the window are suppressed, and once the
+// window passes a single "repeated N times" line with the first and last timestamps is logged.
+// The summary is emitted by a timer even when no further message arrives; Close stops the
+// timer and emits the pending summaries.
+//
+// The window slides on purpose: each repeat extends it, so a message that keeps repeating
+// more often than once per window is summarized once it stops, or by Flush or Close.
+type Deduplicator struct {
+	next EctoLogFunc
+	opts DedupOptions
+
+	mu        sync.Mutex
+	lru       *list.List // *dedupEntry, most recently seen at the front
+	entries   map[string]*list.Element
+	repeating int         // Number of entries with repeats to summarize
+	timer     *time.Timer // Emits the summaries once the window of the least recently seen entry passes
+	armed     bool        // Whether timer is scheduled
+	closed    bool
+}
+
+// dedupEntry tracks the repeats of one fingerprint.
+type dedupEntry struct {
+	fingerprint string
+	msg         EctoLogMessage
+	first       time.Time // Time of the first occurrence
+	last        time.Time // Time of the last occurrence
+	seen        time.Time // Clock time of the last occurrence, which the window is measured from
+	repeated    int
+}
+
+// NewDeduplicator returns a Deduplicator that passes the messages it keeps to next.
+func NewDeduplicator(next EctoLogFunc, opts DedupOptions) *Deduplicator {
+	if opts.Window <= 0 {
+		opts.Window = time.Second
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = defaultDedupMaxEntries
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+	return &Deduplicator{next: next, opts: opts, lru: list.New(), entries: map[string]*list.Element{}}
+}
+
+// Log logs msg through the wrapped log function unless it repeats a recent message.
+// It has the signature of an EctoLogFunc so d.Log can be passed to NewEctoLogger.
+func (d *Deduplicator) Log(msg EctoLogMessage) {
+	fingerprint := d.fingerprint(msg)
+	now := d.opts.Clock.Now()
+	// The first and last seen times are those of the messages, falling back to the clock
+	at := msg.Time
+	if at.IsZero() {
+		at = now
+	}
+
+	d.mu.Lock()
+	summaries := d.expire(now)
+	suppressed := false
+	if elem, ok := d.entries[fingerprint]; ok {
+		entry := elem.Value.(*dedupEntry)
+		if entry.repeated == 0 {
+			d.repeating++
+		}
+		entry.repeated++
+		entry.last = at
+		entry.seen = now
+		d.lru.MoveToFront(elem)
+		suppressed = true
+		d.arm(now)
+	} else {
+		if d.lru.Len() >= d.opts.MaxEntries {
+			if summary, ok := d.remove(d.lru.Back(), now); ok {
+				summaries = append(summaries, summary)
+			}
+		}
+		d.entries[fingerprint] = d.lru.PushFront(&dedupEntry{fingerprint: fingerprint, msg: msg, first: at, last: at, seen: now})
+	}
+	d.mu.Unlock()
+
+	// Log outside the lock so a slow sink does not block other goroutines
+	for _, summary := range summaries {
+		d.next(summary)
+	}
+	if !suppressed {
+		d.next(msg)
+	}
+}
+
+// Flush writes the summary of every message with pending repeats and forgets all fingerprints.
+func (d *Deduplicator) Flush() {
+	now := d.opts.Clock.Now()
+	var summaries []EctoLogMessage
+	d.mu.Lock()
+	for elem := d.lru.Back(); elem != nil; elem = d.lru.Back() {
+		if summary, ok := d.remove(elem, now); ok {
+			summaries = append(summaries, summary)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, summary := range summaries {
+		d.next(summary)
+	}
+}
+
+// Close stops emitting summaries from the timer and writes the summary of every message with
+// pending repeats, as Flush does. Repeats logged after Close are only summarized by a later
+// message or Flush.
+func (d *Deduplicator) Close() {
+	d.mu.Lock()
+	d.closed = true
+	if d.timer != nil {
+		d.timer.Stop()
+		d.armed = false
+	}
+	d.mu.Unlock()
+
+	d.Flush()
+}
+
+// arm schedules the timer for the end of the window of the least recently seen entry, unless
+// it already is, nothing is left to summarize or the deduplicator is closed. d.mu must be held.
+func (d *Deduplicator) arm(now time.Time) {
+	if d.armed || d.closed || d.repeating == 0 {
+		return
+	}
+	d.armed = true
+	delay := d.lru.Back().Value.(*dedupEntry).seen.Add(d.opts.Window).Sub(now)
+	if d.timer == nil {
+		d.timer = time.AfterFunc(delay, d.flushExpired)
+	} else {
+		d.timer.Reset(delay)
+	}
+}
+
+// flushExpired runs on the timer and writes the summaries of the entries whose window has
+// passed, then schedules the timer again for the entries left.
+func (d *Deduplicator) flushExpired() {
+	now := d.opts.Clock.Now()
+	d.mu.Lock()
+	d.armed = false
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	summaries := d.expire(now)
+	d.arm(now)
+	d.mu.Unlock()
+
+	for _, summary := range summaries {
+		d.next(summary)
+	}
+}
+
+// expire removes the fingerprints whose window has passed, oldest first, returning their summaries.
+// d.mu must be held.
+func (d *Deduplicator) expire(now time.Time) []EctoLogMessage {
+	var summaries []EctoLogMessage
+	for elem := d.lru.Back(); elem != nil; elem = d.lru.Back() {
+		if now.Sub(elem.Value.(*dedupEntry).seen) < d.opts.Window {
+			break
+		}
+		if summary, ok := d.remove(elem, now); ok {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
+
+// remove forgets the fingerprint of elem, returning its summary if it was repeated. The summary
+// is stamped with the time of the last occurrence plus the clock time elapsed since, so it
+// stays on the timeline of the messages when they carry their own time. d.mu must be held.
+func (d *Deduplicator) remove(elem *list.Element, now time.Time) (EctoLogMessage, bool) {
+	entry := d.lru.Remove(elem).(*dedupEntry)
+	delete(d.entries, entry.fingerprint)
+	if entry.repeated == 0 {
+		return EctoLogMessage{}, false
+	}
+	d.repeating--
+
+	summary := entry.msg
+	summary.Message = fmt.Sprintf("%s (repeated %d times)", entry.msg.Message, entry.repeated)
+	summary.Time = entry.last.Add(now.Sub(entry.seen))
+	summary.Fields = copyFields(entry.msg.Fields, 3)
+	summary.Fields["repeated"] = entry.repeated
+	summary.Fields["first_seen"] = entry.first
+	summary.Fields["last_seen"] = entry.last
+	return summary, true
+}
+
+// fingerprint identifies messages that are considered identical.
+func (d *Deduplicator) fingerprint(msg EctoLogMessage) string {
+	var b strings.Builder
+	b.WriteString(msg.Level)
+	b.WriteByte(0)
+	b.WriteString(msg.Message)
+	b.WriteByte(0)
+	if msg.Err != nil {
+		b.WriteString(msg.Err.Error())
+	}
+	if len(d.opts.Fields) > 0 {
+		fields := msg.FieldMap()
+		for _, key := range d.opts.Fields {
+			b.WriteByte(0)
+			b.WriteString(key)
+			b.WriteByte('=')
+			fmt.Fprint(&b, fields[key])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package ectologger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicatorCollapsesRepeats(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	dedup := NewDeduplicator(rec.Log, DedupOptions{Window: time.Second, Clock: clock})
+	err := errors.New("connection refused")
+
+	start := clock.Now()
+	for i := 0; i < 5; i++ {
+		dedup.Log(EctoLogMessage{Level: ErrorLevel, Message: "retrying", Err: err})
+		clock.Advance(100 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"retrying"}, rec.Messages())
+
+	clock.Advance(time.Second)
+	dedup.Log(EctoLogMessage{Level: InfoLevel, Message: "other"})
+
+	require.Len(t, rec.messages, 3)
+	summary := rec.messages[1]
+	assert.Equal(t, "retrying (repeated 4 times)", summary.Message)
+	assert.Equal(t, ErrorLevel, summary.Level)
+	assert.Equal(t, err, summary.Err)
+	assert.Equal(t, 4, summary.Fields["repeated"])
+	assert.Equal(t, start, summary.Fields["first_seen"])
+	assert.Equal(t, start.Add(400*time.Millisecond), summary.Fields["last_seen"])
+	assert.Equal(t, clock.Now(), summary.Time)
+	assert.Equal(t, "other", rec.messages[2].Message)
+}
+
+func TestDeduplicatorUsesMessageTimes(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	dedup := NewDeduplicator(rec.Log, DedupOptions{Window: time.Second, Clock: clock})
+
+	// The messages were logged well before the deduplicator sees them, e.g. replayed from a buffer
+	logged := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		dedup.Log(EctoLogMessage{Level: ErrorLevel, Message: "retrying", Time: logged.Add(time.Duration(i) * time.Second)})
+		clock.Advance(100 * time.Millisecond)
+	}
+	clock.Advance(time.Second)
+	dedup.Flush()
+
+	require.Len(t, rec.messages, 2)
+	summary := rec.messages[1]
+	assert.Equal(t, logged, summary.Fields["first_seen"])
+	assert.Equal(t, logged.Add(2*time.Second), summary.Fields["last_seen"])
+	// The summary time follows the last message by the clock time elapsed since it was seen
+	assert.Equal(t, logged.Add(2*time.Second+1100*time.Millisecond), summary.Time)
+}
+
+func TestDeduplicatorDistinguishesSelectedFields(t *testing.T) {
+	rec := &messageRecorder{}
+	dedup := NewDeduplicator(rec.Log, DedupOptions{Fields: []string{"host"}, Clock: newManualClock()})
+
+	dedup.Log(EctoLogMessage{Level: ErrorLevel, Message: "down", Fields: map[string]interface{}{"host": "a", "attempt": 1}})
+	dedup.Log(EctoLogMessage{Level: ErrorLevel, Message: "down", Fields: map[string]interface{}{"host": "a", "attempt": 2}})
+	dedup.Log(EctoLogMessage{Level: ErrorLevel, Message: "down", Fields: map[string]interface{}{"host": "b", "attempt": 1}})
+
+	assert.Equal(t, []string{"down", "down"}, rec.Messages())
+}
+
+func TestDeduplicatorEvictsLeastRecentlySeen(t *testing.T) {
+	rec := &messageRecorder{}
+	dedup := NewDeduplicator(rec.Log, DedupOptions{MaxEntries: 2, Clock: newManualClock()})
+
+	dedup.Log(EctoLogMessage{Message: "a"})
+	dedup.Log(EctoLogMessage{Message: "a"})
+	dedup.Log(EctoLogMessage{Message: "b"})
+	dedup.Log(EctoLogMessage{Message: "c"})
+
+	assert.Equal(t, []string{"a", "b", "a (repeated 1 times)", "c"}, rec.Messages())
+	assert.Len(t, dedup.entries, 2)
+}
+
+func TestDeduplicatorFlush(t *testing.T) {
+	rec := &messageRecorder{}
+	dedup := NewDeduplicator(rec.Log, DedupOptions{Clock: newManualClock()})
+
+	dedup.Log(EctoLogMessage{Message: "a"})
+	dedup.Log(EctoLogMessage{Message: "a"})
+	dedup.Log(EctoLogMessage{Message: "b"})
+	dedup.Flush()
+
+	assert.Equal(t, []string{"a", "b", "a (repeated 1 times)"}, rec.Messages())
+	assert.Empty(t, dedup.entries)
+}
+
+func TestDeduplicatorEmitsSummaryWithoutFurtherMessages(t *testing.T) {
+	rec := &messageRecorder{}
+	dedup := NewDeduplicator(rec.Log, DedupOptions{Window: 10 * time.Millisecond})
+	defer dedup.Close()
+
+	for i := 0; i < 5; i++ {
+		dedup.Log(EctoLogMessage{Level: ErrorLevel, Message: "boom"})
+	}
+
+	assert.Eventually(t, func() bool {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		return len(rec.messages) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"boom", "boom (repeated 4 times)"}, rec.Messages())
+}
+
+func TestDeduplicatorClose(t *testing.T) {
+	rec := &messageRecorder{}
+	dedup := NewDeduplicator(rec.Log, DedupOptions{Window: time.Hour})
+
+	dedup.Log(EctoLogMessage{Message: "a"})
+	dedup.Log(EctoLogMessage{Message: "a"})
+	dedup.Close()
+
+	assert.Equal(t, []string{"a", "a (repeated 1 times)"}, rec.Messages())
+	assert.False(t, dedup.armed)
+	assert.Empty(t, dedup.entries)
+}
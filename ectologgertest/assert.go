@@ -0,0 +1,98 @@
+package ectologgertest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+)
+
+// AssertLogged asserts that rec recorded a message at level whose text contains msgSubstr and
+// whose fields include fields. An empty level matches every level, and fields may name only
+// the keys of interest. Values are compared like assert.EqualValues.
+func AssertLogged(t assert.TestingT, rec *Recorder, level, msgSubstr string, fields map[string]interface{}, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	for _, msg := range rec.Messages() {
+		if matches(msg, level, msgSubstr, fields) {
+			return true
+		}
+	}
+	return assert.Fail(t, fmt.Sprintf("No message at level %q containing %q with fields %v was logged.\nLogged:\n%s",
+		level, msgSubstr, fields, format(rec.Messages())), msgAndArgs...)
+}
+
+// AssertNotLogged asserts that rec recorded no message matching level, msgSubstr and fields,
+// as described for AssertLogged.
+func AssertNotLogged(t assert.TestingT, rec *Recorder, level, msgSubstr string, fields map[string]interface{}, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	var found Messages
+	for _, msg := range rec.Messages() {
+		if matches(msg, level, msgSubstr, fields) {
+			found = append(found, msg)
+		}
+	}
+	if len(found) == 0 {
+		return true
+	}
+	return assert.Fail(t, fmt.Sprintf("Unexpected messages at level %q containing %q with fields %v were logged:\n%s",
+		level, msgSubstr, fields, format(found)), msgAndArgs...)
+}
+
+// AssertNoErrors asserts that rec recorded no message at ErrorLevel or above.
+func AssertNoErrors(t assert.TestingT, rec *Recorder, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	var errs Messages
+	for _, msg := range rec.Messages() {
+		if ectologger.LevelEnabled(msg.Level, ectologger.ErrorLevel) {
+			errs = append(errs, msg)
+		}
+	}
+	if len(errs) == 0 {
+		return true
+	}
+	return assert.Fail(t, fmt.Sprintf("Unexpected error messages were logged:\n%s", format(errs)), msgAndArgs...)
+}
+
+// matches reports whether msg is at level, contains msgSubstr and includes fields.
+func matches(msg ectologger.EctoLogMessage, level, msgSubstr string, fields map[string]interface{}) bool {
+	if level != "" && msg.Level != level {
+		return false
+	}
+	if !strings.Contains(msg.Message, msgSubstr) {
+		return false
+	}
+	logged := msg.FieldMap()
+	for k, want := range fields {
+		got, ok := logged[k]
+		if !ok || !assert.ObjectsAreEqualValues(want, got) {
+			return false
+		}
+	}
+	return true
+}
+
+// format renders messages one per line for failure output.
+func format(messages Messages) string {
+	if len(messages) == 0 {
+		return "\t(none)"
+	}
+	var b strings.Builder
+	for _, msg := range messages {
+		fmt.Fprintf(&b, "\t%s: %s %v", msg.Level, msg.Message, msg.FieldMap())
+		if msg.Err != nil {
+			fmt.Fprintf(&b, " error=%v", msg.Err)
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
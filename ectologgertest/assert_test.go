@@ -0,0 +1,47 @@
+package ectologgertest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockT records the failures of assertions that are expected to fail.
+type mockT struct {
+	failures []string
+}
+
+func (m *mockT) Errorf(format string, args ...interface{}) {
+	m.failures = append(m.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertLogged(t *testing.T) {
+	rec := NewRecorder()
+	rec.WithFields(map[string]interface{}{"user": "jane", "attempt": 3}).Info("user logged in")
+
+	AssertLogged(t, rec, ectologger.InfoLevel, "logged in", map[string]interface{}{"user": "jane"})
+	AssertLogged(t, rec, "", "", map[string]interface{}{"attempt": int64(3)})
+	AssertNotLogged(t, rec, ectologger.ErrorLevel, "", nil)
+
+	mock := &mockT{}
+	assert.False(t, AssertLogged(mock, rec, ectologger.InfoLevel, "logged in", map[string]interface{}{"user": "john"}))
+	assert.False(t, AssertLogged(mock, rec, ectologger.WarnLevel, "logged in", nil))
+	assert.False(t, AssertNotLogged(mock, rec, "", "user", nil))
+	assert.Len(t, mock.failures, 3)
+	assert.Contains(t, mock.failures[0], "info: user logged in", "failures list what was logged")
+}
+
+func TestAssertNoErrors(t *testing.T) {
+	rec := NewRecorder()
+	rec.Warn("slow query")
+	AssertNoErrors(t, rec)
+
+	rec.Error("query failed")
+	mock := &mockT{}
+	assert.False(t, AssertNoErrors(mock, rec))
+	assert.Len(t, mock.failures, 1)
+	assert.Contains(t, mock.failures[0], "error: query failed")
+	assert.NotContains(t, mock.failures[0], "slow query")
+}
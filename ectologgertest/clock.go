@@ -0,0 +1,43 @@
+package ectologgertest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+)
+
+// FakeClock is an ectologger.Clock that only moves when told to, for deterministic timestamps
+// and for testing samplers, rate limiters and other time based wrappers. It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+var _ ectologger.Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns a FakeClock reading start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
@@ -0,0 +1,42 @@
+package ectologgertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	rec := NewRecorder(ectologger.WithClock(clock))
+
+	rec.Info("first")
+	clock.Advance(time.Second)
+	rec.Info("second")
+	clock.Set(start.Add(time.Hour))
+	rec.Info("third")
+
+	messages := rec.Messages()
+	require.Len(t, messages, 3)
+	assert.Equal(t, start, messages[0].Time)
+	assert.Equal(t, start.Add(time.Second), messages[1].Time)
+	assert.Equal(t, start.Add(time.Hour), messages[2].Time)
+}
+
+func TestFakeClockDrivesWrappers(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	rec := NewRecorder()
+	limiter := ectologger.NewRateLimiter(rec.Log, ectologger.RateLimiterOptions{Rate: 1, Clock: clock})
+	logger := ectologger.NewEctoLogger(limiter.Log, ectologger.WithClock(clock))
+
+	logger.Info("allowed")
+	logger.Info("suppressed")
+	clock.Advance(time.Second)
+	logger.Info("allowed again")
+
+	assert.Equal(t, []string{"allowed", "log messages suppressed by rate limit", "allowed again"}, rec.Messages().Texts())
+}
@@ -0,0 +1,120 @@
+package ectologgertest
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// UpdateGoldenEnv is the environment variable that, set to a true value such as 1, makes
+// AssertGolden rewrite golden files instead of comparing with them.
+const UpdateGoldenEnv = "ECTOLOG_UPDATE_GOLDEN"
+
+// updateFlag is the name of the flag that makes AssertGolden rewrite golden files.
+const updateFlag = "update"
+
+// updateGolden is the value of the -update flag defined by RegisterUpdateFlag.
+var updateGolden bool
+
+var (
+	// timestampPattern matches RFC 3339 timestamps, with or without fractional seconds.
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+	// callerPattern matches caller locations such as pkg/file.go:42.
+	callerPattern = regexp.MustCompile(`[\w./-]+\.go:\d+`)
+)
+
+// CaptureStdOutput returns what the built-in encoders, such as ectologger.DefaultEctoLogFunc
+// and ectologger.LogfmtEctoLogFunc, write to the standard library logger while fn runs.
+// The logger's flags and prefix are cleared during the capture. As the standard library logger
+// is global, tests using it must not run in parallel with other tests that log through it.
+func CaptureStdOutput(fn func()) []byte {
+	var buf bytes.Buffer
+	w, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	log.SetPrefix("")
+	defer func() {
+		log.SetOutput(w)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}()
+
+	fn()
+	return buf.Bytes()
+}
+
+// Normalize replaces the parts of encoder output that change between runs: timestamps become
+// <time> and caller locations become <caller>.
+func Normalize(output []byte) []byte {
+	output = timestampPattern.ReplaceAll(output, []byte("<time>"))
+	return callerPattern.ReplaceAll(output, []byte("<caller>"))
+}
+
+// AssertGolden normalizes output and compares it with the golden file testdata/<name>.golden,
+// relative to the package under test. Run the tests with -update, once RegisterUpdateFlag has
+// defined it, or with ECTOLOG_UPDATE_GOLDEN=1 to write the golden files from the current output
+// after an intended format change. A boolean -update flag the test package defines itself is
+// honored too.
+func AssertGolden(t assert.TestingT, name string, output []byte, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	output = Normalize(output)
+	path := filepath.Join("testdata", name+".golden")
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return assert.Fail(t, "Failed to create testdata directory: "+err.Error(), msgAndArgs...)
+		}
+		if err := os.WriteFile(path, output, 0o644); err != nil {
+			return assert.Fail(t, "Failed to update golden file: "+err.Error(), msgAndArgs...)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return assert.Fail(t, "Failed to read golden file, run the tests with "+UpdateGoldenEnv+"=1 to create it: "+err.Error(), msgAndArgs...)
+	}
+	if len(msgAndArgs) == 0 {
+		msgAndArgs = []interface{}{"output differs from %s, run the tests with %s=1 if the change is intended", path, UpdateGoldenEnv}
+	}
+	return assert.Equal(t, string(want), string(output), msgAndArgs...)
+}
+
+// RegisterUpdateFlag defines the -update flag that makes AssertGolden rewrite golden files.
+// Call it from an init function or TestMain of the test package, before the flags are parsed:
+//
+//	func init() { ectologgertest.RegisterUpdateFlag(flag.CommandLine) }
+//
+// Importing the package defines no flags, so test packages that define -update themselves keep
+// working.
+func RegisterUpdateFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&updateGolden, updateFlag, false, "rewrite the golden files with the current output")
+}
+
+// updating reports whether the -update flag or ECTOLOG_UPDATE_GOLDEN is set.
+func updating() bool {
+	if updateGolden {
+		return true
+	}
+	if update, err := strconv.ParseBool(os.Getenv(UpdateGoldenEnv)); err == nil && update {
+		return true
+	}
+	f := flag.Lookup(updateFlag)
+	if f == nil {
+		return false
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	update, _ := getter.Get().(bool)
+	return update
+}
@@ -0,0 +1,87 @@
+package ectologgertest
+
+import (
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() { RegisterUpdateFlag(flag.CommandLine) }
+
+// logSamples logs a message of each shape the encoders have to handle.
+func logSamples(logger ectologger.Logger) {
+	logger.Info("plain message")
+	logger.WithFields(map[string]interface{}{"user": "jane", "attempt": 3, "nested": map[string]interface{}{"id": 7}}).Warn("map fields")
+	logger.WithTypedFields(
+		ectologger.String("quoted", `say "hi"`),
+		ectologger.Int64("count", 42),
+		ectologger.Float64("ratio", 0.5),
+		ectologger.Bool("ok", true),
+		ectologger.Duration("elapsed", 1500*time.Millisecond),
+		ectologger.Object("request", ectologger.String("method", "GET"), ectologger.String("path", "/users")),
+	).Debug("typed fields")
+	logger.WithField("service", "billing").WithGroup("db").WithField("table", "invoices").Info("grouped fields")
+	logger.WithError(errors.New("connection refused")).Error("with error")
+}
+
+func TestGoldenDefaultEncoder(t *testing.T) {
+	output := CaptureStdOutput(func() {
+		logSamples(ectologger.NewEctoLogger(ectologger.DefaultEctoLogFunc))
+	})
+	AssertGolden(t, "default_json", output)
+}
+
+func TestGoldenLogfmtEncoder(t *testing.T) {
+	output := CaptureStdOutput(func() {
+		logSamples(ectologger.NewEctoLogger(ectologger.LogfmtEctoLogFunc))
+	})
+	AssertGolden(t, "logfmt", output)
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t,
+		`{"caller":"<caller>","time":"<time>"} time=<time> at=<caller>`,
+		string(Normalize([]byte(`{"caller":"pkg/handler.go:42","time":"2024-05-01T10:00:00.123+02:00"} time=2024-05-01T08:00:00Z at=main.go:7`))))
+}
+
+func TestAssertGoldenMismatch(t *testing.T) {
+	if updating() {
+		t.Skip("the mismatches would be written to the golden files")
+	}
+
+	mock := &mockT{}
+	assert.False(t, AssertGolden(mock, "logfmt", []byte("level=info message=changed\n")))
+	assert.Len(t, mock.failures, 1)
+	assert.Contains(t, mock.failures[0], "ECTOLOG_UPDATE_GOLDEN=1")
+
+	assert.False(t, AssertGolden(mock, "missing", nil))
+	assert.Contains(t, mock.failures[1], "run the tests with ECTOLOG_UPDATE_GOLDEN=1 to create it")
+}
+
+func TestRegisterUpdateFlag(t *testing.T) {
+	if updating() {
+		t.Skip("the golden files are being updated")
+	}
+	defer func() { updateGolden = false }()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterUpdateFlag(fs)
+	assert.False(t, updating())
+	assert.NoError(t, fs.Parse([]string{"-update"}))
+	assert.True(t, updating())
+}
+
+func TestUpdatingFromEnv(t *testing.T) {
+	if updateGolden {
+		t.Skip("the -update flag is set")
+	}
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	assert.True(t, updating())
+	t.Setenv(UpdateGoldenEnv, "false")
+	assert.False(t, updating())
+}
@@ -0,0 +1,121 @@
+// Package ectologgertest provides loggers and assertions for testing code that logs through ectologger.
+package ectologgertest
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+)
+
+// Messages is a list of recorded messages with helpers to narrow it down.
+type Messages []ectologger.EctoLogMessage
+
+// FilterLevel returns the messages logged at level.
+func (m Messages) FilterLevel(level string) Messages {
+	return m.filter(func(msg ectologger.EctoLogMessage) bool {
+		return msg.Level == level
+	})
+}
+
+// FilterMessage returns the messages whose text matches the regular expression pattern.
+// It panics if pattern does not compile, like regexp.MustCompile.
+func (m Messages) FilterMessage(pattern string) Messages {
+	re := regexp.MustCompile(pattern)
+	return m.filter(func(msg ectologger.EctoLogMessage) bool {
+		return re.MatchString(msg.Message)
+	})
+}
+
+// FilterField returns the messages with a field key equal to value. Values are compared like
+// assert.EqualValues, so an int matches the int64 of a typed field. Nested fields are
+// addressed by their root key.
+func (m Messages) FilterField(key string, value interface{}) Messages {
+	return m.filter(func(msg ectologger.EctoLogMessage) bool {
+		v, ok := msg.FieldMap()[key]
+		return ok && assert.ObjectsAreEqualValues(value, v)
+	})
+}
+
+// Texts returns the text of each message.
+func (m Messages) Texts() []string {
+	texts := make([]string, len(m))
+	for i, msg := range m {
+		texts[i] = msg.Message
+	}
+	return texts
+}
+
+// filter returns the messages keep returns true for.
+func (m Messages) filter(keep func(msg ectologger.EctoLogMessage) bool) Messages {
+	var result Messages
+	for _, msg := range m {
+		if keep(msg) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Recorder is a Logger that stores every message logged through it, for assertions in tests.
+// It is safe for concurrent use, so it can be handed to code logging from several goroutines.
+// LogValuers are resolved when a message is recorded.
+type Recorder struct {
+	ectologger.Logger
+
+	mu       sync.Mutex
+	messages Messages
+}
+
+// NewRecorder returns a Recorder. The options configure its logger, e.g. processors under test.
+func NewRecorder(opts ...ectologger.Option) *Recorder {
+	r := &Recorder{}
+	r.Logger = ectologger.NewEctoLogger(r.Log, opts...)
+	return r
+}
+
+// Log records msg. It has the signature of an EctoLogFunc so r.Log can be passed to adapters
+// and wrappers such as ectologger.NewSampler.
+func (r *Recorder) Log(msg ectologger.EctoLogMessage) {
+	msg = msg.Resolve()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+}
+
+// Messages returns a copy of the recorded messages in the order they were logged.
+func (r *Recorder) Messages() Messages {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(Messages(nil), r.messages...)
+}
+
+// Len returns the number of recorded messages.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.messages)
+}
+
+// Reset forgets the recorded messages, e.g. between the cases of a table test.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = nil
+}
+
+// FilterLevel returns the recorded messages logged at level.
+func (r *Recorder) FilterLevel(level string) Messages {
+	return r.Messages().FilterLevel(level)
+}
+
+// FilterMessage returns the recorded messages whose text matches the regular expression pattern.
+func (r *Recorder) FilterMessage(pattern string) Messages {
+	return r.Messages().FilterMessage(pattern)
+}
+
+// FilterField returns the recorded messages with a field key equal to value.
+func (r *Recorder) FilterField(key string, value interface{}) Messages {
+	return r.Messages().FilterField(key, value)
+}
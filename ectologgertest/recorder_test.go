@@ -0,0 +1,56 @@
+package ectologgertest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	rec.WithField("user", "jane").Info("user logged in")
+	rec.WithTypedFields(ectologger.Int64("attempt", 2)).Warn("retrying request")
+	rec.WithError(errors.New("timeout")).Error("request failed")
+
+	assert.Equal(t, 3, rec.Len())
+	assert.Equal(t, []string{"retrying request"}, rec.FilterLevel(ectologger.WarnLevel).Texts())
+	assert.Equal(t, []string{"retrying request", "request failed"}, rec.FilterMessage(`^re(try|quest)`).Texts())
+	assert.Equal(t, []string{"user logged in"}, rec.FilterField("user", "jane").Texts())
+	assert.Equal(t, []string{"retrying request"}, rec.FilterField("attempt", 2).Texts(), "values compare like EqualValues")
+	assert.Empty(t, rec.FilterLevel(ectologger.InfoLevel).FilterField("user", "john"))
+
+	rec.Reset()
+	assert.Equal(t, 0, rec.Len())
+}
+
+func TestRecorderResolvesValues(t *testing.T) {
+	calls := 0
+	rec := NewRecorder()
+	rec.WithField("lazy", ectologger.LogValuerFunc(func() interface{} {
+		calls++
+		return "resolved"
+	})).Info("test message")
+
+	assert.Equal(t, "resolved", rec.Messages()[0].FieldMap()["lazy"])
+	assert.Equal(t, 1, calls)
+}
+
+func TestRecorderConcurrent(t *testing.T) {
+	rec := NewRecorder()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				rec.Info("test message")
+				_ = rec.FilterLevel(ectologger.InfoLevel)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, rec.Len())
+}
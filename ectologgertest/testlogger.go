@@ -0,0 +1,297 @@
+package ectologgertest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Gobusters/ectologger"
+)
+
+// TB is the part of testing.TB used by NewT. *testing.T, *testing.B and *testing.F implement it.
+type TB interface {
+	Helper()
+	Name() string
+	Log(args ...interface{})
+	Error(args ...interface{})
+	Cleanup(func())
+}
+
+// TOption configures a logger created by NewT.
+type TOption func(*tConfig)
+
+// tConfig holds the configuration built from TOptions.
+type tConfig struct {
+	failOnError bool
+	loggerOpts  []ectologger.Option
+}
+
+// FailOnError marks the test as failed when a message at ErrorLevel or above is logged.
+func FailOnError() TOption {
+	return func(c *tConfig) {
+		c.failOnError = true
+	}
+}
+
+// WithLoggerOptions configures the underlying logger, e.g. with processors under test.
+func WithLoggerOptions(opts ...ectologger.Option) TOption {
+	return func(c *tConfig) {
+		c.loggerOpts = append(c.loggerOpts, opts...)
+	}
+}
+
+// captureKey is the context key of the capture a single log call writes to.
+type captureKey struct{}
+
+// capture collects the messages that reached the end of the pipeline during one log call.
+type capture struct {
+	messages []ectologger.EctoLogMessage
+}
+
+// tState is shared by a test logger and the loggers derived from it.
+type tState struct {
+	t           TB
+	failOnError bool
+
+	mu   sync.Mutex
+	done bool // Set once the test has completed
+}
+
+// testLogger is a Logger writing through t.Log. The message passes through a regular
+// EctoLogger, so fields, groups and processors behave as in production, but it is written
+// by the logging method itself once the pipeline returns. Every frame between the caller
+// and t.Log is then a helper, so the test output points at the line that logged.
+type testLogger struct {
+	state  *tState
+	logger ectologger.Logger
+	ctx    context.Context
+}
+
+// NewT returns a Logger that writes each message through t.Log, so the output is attached to
+// the test or subtest t belongs to, reported at the line that logged it, and only shown when
+// the test fails or runs with -v. Messages logged after the test completed, e.g. by a
+// goroutine it leaked, are written to stderr instead of panicking.
+func NewT(t TB, opts ...TOption) ectologger.Logger {
+	cfg := &tConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	state := &tState{t: t, failOnError: cfg.failOnError}
+	t.Cleanup(func() {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		state.done = true
+	})
+
+	logger := ectologger.NewEctoLogger(func(msg ectologger.EctoLogMessage) {
+		var c *capture
+		if msg.Ctx != nil {
+			c, _ = msg.Ctx.Value(captureKey{}).(*capture)
+		}
+		if c != nil {
+			c.messages = append(c.messages, msg.Resolve())
+			return
+		}
+		// Logged from outside a logging call, e.g. by a sampler timer, or by a processor that
+		// built a message without its context: write it straight away
+		state.write(msg.Resolve())
+	}, cfg.loggerOpts...)
+	return &testLogger{state: state, logger: logger, ctx: context.Background()}
+}
+
+// log runs the message through the pipeline and writes what comes out.
+func (l *testLogger) log(ctx context.Context, level, msg string) {
+	l.state.t.Helper()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c := &capture{}
+	ectologger.LogAt(l.logger.WithContext(context.WithValue(ctx, captureKey{}, c)), level, msg)
+
+	for _, m := range c.messages {
+		l.state.write(m)
+	}
+}
+
+// write writes msg through the test, unless the test has completed.
+func (s *tState) write(msg ectologger.EctoLogMessage) {
+	s.t.Helper()
+	line := formatLine(msg)
+
+	// Hold the lock while writing so the test cannot complete in between
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		fmt.Fprintf(os.Stderr, "ectologgertest: log after %s completed: %s\n", s.t.Name(), line)
+		return
+	}
+	if s.failOnError && ectologger.LevelEnabled(msg.Level, ectologger.ErrorLevel) {
+		s.t.Error(line)
+		return
+	}
+	s.t.Log(line)
+}
+
+// formatLine renders msg as its level, text, sorted fields and error.
+func formatLine(msg ectologger.EctoLogMessage) string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(msg.Level))
+	b.WriteByte(' ')
+	b.WriteString(msg.Message)
+
+	fields := msg.FieldMap()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, fields[k])
+	}
+	if msg.Err != nil {
+		fmt.Fprintf(&b, " error=%q", msg.Err.Error())
+	}
+	return b.String()
+}
+
+// with returns a copy of l logging through logger.
+func (l *testLogger) with(logger ectologger.Logger) *testLogger {
+	return &testLogger{state: l.state, logger: logger, ctx: l.ctx}
+}
+
+// WithFields returns a new Logger with the given fields added to the logging context.
+func (l *testLogger) WithFields(fields map[string]interface{}) ectologger.Logger {
+	return l.with(l.logger.WithFields(fields))
+}
+
+// WithField returns a new Logger with the given key-value pair added to the logging context.
+func (l *testLogger) WithField(key string, value interface{}) ectologger.Logger {
+	return l.with(l.logger.WithField(key, value))
+}
+
+// WithTypedFields returns a new Logger with the given typed fields added to the logging context.
+func (l *testLogger) WithTypedFields(fields ...ectologger.Field) ectologger.Logger {
+	return l.with(l.logger.WithTypedFields(fields...))
+}
+
+// WithGroup returns a new Logger that nests all subsequently added fields under name.
+func (l *testLogger) WithGroup(name string) ectologger.Logger {
+	return l.with(l.logger.WithGroup(name))
+}
+
+// Named returns a new Logger with name appended to the current name, separated by a dot.
+func (l *testLogger) Named(name string) ectologger.Logger {
+	return l.with(l.logger.Named(name))
+}
+
+// V returns a Logger writing through the test if verbosity level is enabled. Module rules
+// match the call site inside this package rather than the test's.
+func (l *testLogger) V(level int) ectologger.Logger {
+	return l.with(l.logger.V(level))
+}
+
+// WithContext returns a new Logger with the given context added to the logging context.
+func (l *testLogger) WithContext(ctx context.Context) ectologger.Logger {
+	c := l.with(l.logger)
+	c.ctx = ctx
+	return c
+}
+
+// WithError returns a new Logger with the given error added to the logging context.
+func (l *testLogger) WithError(err error) ectologger.Logger {
+	return l.with(l.logger.WithError(err))
+}
+
+// Debug logs a message at the Debug level.
+func (l *testLogger) Debug(msg string) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.DebugLevel, msg)
+}
+func (l *testLogger) Debugf(format string, args ...any) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.DebugLevel, fmt.Sprintf(format, args...))
+}
+func (l *testLogger) DebugContext(ctx context.Context, msg string) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.DebugLevel, msg)
+}
+func (l *testLogger) DebugContextf(ctx context.Context, format string, args ...any) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.DebugLevel, fmt.Sprintf(format, args...))
+}
+
+// Info logs a message at the Info level.
+func (l *testLogger) Info(msg string) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.InfoLevel, msg)
+}
+func (l *testLogger) Infof(format string, args ...any) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.InfoLevel, fmt.Sprintf(format, args...))
+}
+func (l *testLogger) InfoContext(ctx context.Context, msg string) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.InfoLevel, msg)
+}
+func (l *testLogger) InfoContextf(ctx context.Context, format string, args ...any) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.InfoLevel, fmt.Sprintf(format, args...))
+}
+
+// Warn logs a message at the Warn level.
+func (l *testLogger) Warn(msg string) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.WarnLevel, msg)
+}
+func (l *testLogger) Warnf(format string, args ...any) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.WarnLevel, fmt.Sprintf(format, args...))
+}
+func (l *testLogger) WarnContext(ctx context.Context, msg string) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.WarnLevel, msg)
+}
+func (l *testLogger) WarnContextf(ctx context.Context, format string, args ...any) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.WarnLevel, fmt.Sprintf(format, args...))
+}
+
+// Error logs a message at the Error level.
+func (l *testLogger) Error(msg string) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.ErrorLevel, msg)
+}
+func (l *testLogger) Errorf(format string, args ...any) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.ErrorLevel, fmt.Sprintf(format, args...))
+}
+func (l *testLogger) ErrorContext(ctx context.Context, msg string) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.ErrorLevel, msg)
+}
+func (l *testLogger) ErrorContextf(ctx context.Context, format string, args ...any) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+// Fatal logs a message at the Fatal level.
+func (l *testLogger) Fatal(msg string) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.FatalLevel, msg)
+}
+func (l *testLogger) Fatalf(format string, args ...any) {
+	l.state.t.Helper()
+	l.log(l.ctx, ectologger.FatalLevel, fmt.Sprintf(format, args...))
+}
+func (l *testLogger) FatalContext(ctx context.Context, msg string) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.FatalLevel, msg)
+}
+func (l *testLogger) FatalContextf(ctx context.Context, format string, args ...any) {
+	l.state.t.Helper()
+	l.log(ctx, ectologger.FatalLevel, fmt.Sprintf(format, args...))
+}
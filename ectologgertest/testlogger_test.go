@@ -0,0 +1,177 @@
+package ectologgertest
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTB records what a test logger writes. Messages logged from timers reach it from other
+// goroutines, so it is safe for concurrent use.
+type fakeTB struct {
+	mu       sync.Mutex
+	logs     []string
+	errors   []string
+	helpers  map[string]bool
+	cleanups []func()
+}
+
+func newFakeTB() *fakeTB {
+	return &fakeTB{helpers: map[string]bool{}}
+}
+
+func (f *fakeTB) Helper() {
+	pc, _, _, _ := runtime.Caller(1)
+	name := runtime.FuncForPC(pc).Name()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.helpers[name[strings.LastIndex(name, ".")+1:]] = true
+}
+func (f *fakeTB) Name() string { return "TestFake" }
+func (f *fakeTB) Log(args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logs = append(f.logs, args[0].(string))
+}
+func (f *fakeTB) Error(args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors = append(f.errors, args[0].(string))
+}
+func (f *fakeTB) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+
+// Logs returns a copy of the lines written with Log.
+func (f *fakeTB) Logs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.logs...)
+}
+func (f *fakeTB) complete() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
+
+func TestNewT(t *testing.T) {
+	tb := newFakeTB()
+	logger := NewT(tb)
+
+	logger.WithField("user", "jane").WithGroup("db").WithField("table", "users").Info("user loaded")
+	logger.WithError(errors.New("timeout")).Errorf("query %d failed", 3)
+
+	assert.Equal(t, []string{
+		"INFO user loaded db=map[table:users] user=jane",
+		`ERROR query 3 failed error="timeout"`,
+	}, tb.logs)
+	assert.Empty(t, tb.errors)
+
+	// Every frame between the caller and t.Log is a helper, so t.Log reports the caller's line
+	assert.True(t, tb.helpers["Info"])
+	assert.True(t, tb.helpers["Errorf"])
+	assert.True(t, tb.helpers["log"])
+	assert.True(t, tb.helpers["write"])
+}
+
+func TestNewTNamed(t *testing.T) {
+	tb := newFakeTB()
+	NewT(tb).Named("db").Named("pool").Info("pool opened")
+
+	assert.Equal(t, []string{"INFO pool opened logger=db.pool"}, tb.logs)
+}
+
+func TestNewTVerbosity(t *testing.T) {
+	verbosity, err := ectologger.NewVerbosity(1, "")
+	require.NoError(t, err)
+	tb := newFakeTB()
+	logger := NewT(tb, WithLoggerOptions(ectologger.WithVerbosity(verbosity)))
+
+	logger.V(1).Info("logged")
+	logger.V(2).Info("dropped")
+
+	assert.Equal(t, []string{"INFO logged"}, tb.logs)
+}
+
+func TestNewTFailOnError(t *testing.T) {
+	tb := newFakeTB()
+	logger := NewT(tb, FailOnError())
+
+	logger.Warn("slow query")
+	logger.Error("query failed")
+	logger.Fatal("giving up")
+
+	assert.Equal(t, []string{"WARN slow query"}, tb.logs)
+	assert.Equal(t, []string{"ERROR query failed", "FATAL giving up"}, tb.errors)
+}
+
+func TestNewTProcessors(t *testing.T) {
+	tb := newFakeTB()
+	logger := NewT(tb, WithLoggerOptions(ectologger.WithProcessors(
+		ectologger.MinLevel(ectologger.InfoLevel),
+		ectologger.Redact("password"),
+	)))
+
+	logger.Debug("dropped")
+	logger.WithField("password", "secret").Info("login")
+
+	assert.Equal(t, []string{"INFO login password=[REDACTED]"}, tb.logs)
+}
+
+func TestNewTProcessorWithoutContext(t *testing.T) {
+	tb := newFakeTB()
+	split := func(msg ectologger.EctoLogMessage, next ectologger.EctoLogFunc) {
+		next(msg)
+		// A new message built from scratch carries no context
+		next(ectologger.EctoLogMessage{Level: ectologger.WarnLevel, Message: "split from " + msg.Message})
+	}
+	logger := NewT(tb, WithLoggerOptions(ectologger.WithProcessors(split)))
+
+	logger.Info("original")
+
+	// The message without a context is written as soon as it reaches the end of the pipeline
+	assert.Equal(t, []string{"WARN split from original", "INFO original"}, tb.logs)
+}
+
+func TestNewTWritesMessagesFromTimers(t *testing.T) {
+	tb := newFakeTB()
+	var sampler *ectologger.Sampler
+	logger := NewT(tb, WithLoggerOptions(ectologger.WithProcessors(func(msg ectologger.EctoLogMessage, next ectologger.EctoLogFunc) {
+		if sampler == nil {
+			sampler = ectologger.NewSampler(next, ectologger.SamplerOptions{Interval: 10 * time.Millisecond, First: 1})
+		}
+		sampler.Log(msg)
+	})))
+
+	logger.Info("repeated")
+	logger.Info("repeated")
+	defer sampler.Close()
+
+	// The summary is logged by the sampler's timer, with no logging call to write it
+	assert.Eventually(t, func() bool { return len(tb.Logs()) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "INFO repeated", tb.Logs()[0])
+	assert.Contains(t, tb.Logs()[1], "sampled out log messages")
+}
+
+func TestNewTAfterCompletion(t *testing.T) {
+	tb := newFakeTB()
+	logger := NewT(tb)
+	tb.complete()
+
+	require.NotPanics(t, func() { logger.Info("late message") })
+	assert.Empty(t, tb.logs)
+}
+
+func TestNewTWithTesting(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			logger := NewT(t)
+			logger.WithField("case", name).Info("running")
+		})
+	}
+}
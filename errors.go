@@ -0,0 +1,41 @@
+package ectologger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// ErrDuplicateField is reported when a field is added with a key that already exists
+// and the logger uses the CollisionReport policy.
+var ErrDuplicateField = errors.New("ectologger: duplicate field")
+
+// ErrorHandler is called with errors that happen inside ectologger itself, such as
+// duplicate fields or panicking hooks, which cannot be returned to the logging call site.
+type ErrorHandler func(err error)
+
+var errorHandler atomic.Value // holds an ErrorHandler
+
+// DefaultErrorHandler writes internal errors to stderr.
+// It bypasses the log package so reporting never feeds back into a logger writing through it.
+func DefaultErrorHandler(err error) {
+	fmt.Fprintf(os.Stderr, "%v\n", err)
+}
+
+// SetErrorHandler replaces the handler for internal errors. Passing nil restores DefaultErrorHandler.
+func SetErrorHandler(handler ErrorHandler) {
+	if handler == nil {
+		handler = DefaultErrorHandler
+	}
+	errorHandler.Store(handler)
+}
+
+// reportError passes err to the current error handler.
+func reportError(err error) {
+	handler, _ := errorHandler.Load().(ErrorHandler)
+	if handler == nil {
+		handler = DefaultErrorHandler
+	}
+	handler(err)
+}
@@ -0,0 +1,199 @@
+package ectologger
+
+import (
+	"time"
+)
+
+// FieldType identifies which member of a Field holds its value.
+type FieldType uint8
+
+const (
+	// UnknownType is the zero value of FieldType and is never produced by the constructors.
+	UnknownType FieldType = iota
+	// StringType indicates the value is stored in Field.String.
+	StringType
+	// Int64Type indicates the value is stored in Field.Integer.
+	Int64Type
+	// Float64Type indicates the value is stored in Field.Float.
+	Float64Type
+	// BoolType indicates the value is stored in Field.Integer as 1 (true) or 0 (false).
+	BoolType
+	// DurationType indicates the value is stored in Field.Integer as nanoseconds.
+	DurationType
+	// TimeType indicates the value is stored in Field.Interface as a time.Time.
+	TimeType
+	// ErrorType indicates the value is stored in Field.Interface as an error.
+	ErrorType
+	// AnyType indicates the value is stored in Field.Interface without further typing.
+	AnyType
+	// ObjectType indicates Field.Interface holds the nested []Field of an object.
+	ObjectType
+	// ArrayType indicates Field.Interface holds the []interface{} elements of an array.
+	ArrayType
+	// NamespaceType indicates all subsequent fields are nested under Field.Key.
+	NamespaceType
+	// SkipType indicates the field carries no value and is ignored by encoders.
+	SkipType
+)
+
+// Field is a strongly typed key-value pair that can be added to a Logger with WithTypedFields.
+// Unlike the map based WithFields, typed fields keep their order and their type, so encoders
+// can write them without reflection.
+type Field struct {
+	Key       string      // The key of the field
+	Type      FieldType   // Which of the value members below is set
+	Integer   int64       // The value of Int64Type, BoolType and DurationType fields
+	Float     float64     // The value of Float64Type fields
+	String    string      // The value of StringType fields
+	Interface interface{} // The value of TimeType, ErrorType, AnyType, ObjectType and ArrayType fields
+}
+
+// String constructs a field with the given key and string value.
+func String(key string, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+// Int64 constructs a field with the given key and int64 value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: Int64Type, Integer: value}
+}
+
+// Float64 constructs a field with the given key and float64 value.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: Float64Type, Float: value}
+}
+
+// Bool constructs a field with the given key and bool value.
+func Bool(key string, value bool) Field {
+	var i int64
+	if value {
+		i = 1
+	}
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+// Duration constructs a field with the given key and duration value.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Time constructs a field with the given key and time value.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, Interface: value}
+}
+
+// Err constructs a field with the key "error" holding the given error.
+// A nil error produces a SkipType field that encoders ignore.
+func Err(err error) Field {
+	if err == nil {
+		return Field{Type: SkipType}
+	}
+	return Field{Key: "error", Type: ErrorType, Interface: err}
+}
+
+// Object constructs a field that nests the given fields under key.
+func Object(key string, fields ...Field) Field {
+	return Field{Key: key, Type: ObjectType, Interface: fields}
+}
+
+// Array constructs a field with the given key holding an ordered list of values.
+func Array(key string, values ...interface{}) Field {
+	return Field{Key: key, Type: ArrayType, Interface: values}
+}
+
+// Namespace constructs a field that nests every field added after it under key.
+func Namespace(key string) Field {
+	return Field{Key: key, Type: NamespaceType}
+}
+
+// Any constructs a field with the given key and value, picking the most specific
+// field type for the value. Values without a specific type are stored as AnyType.
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int64(key, int64(v))
+	case int8:
+		return Int64(key, int64(v))
+	case int16:
+		return Int64(key, int64(v))
+	case int32:
+		return Int64(key, int64(v))
+	case int64:
+		return Int64(key, v)
+	case uint8:
+		return Int64(key, int64(v))
+	case uint16:
+		return Int64(key, int64(v))
+	case uint32:
+		return Int64(key, int64(v))
+	case float32:
+		return Float64(key, float64(v))
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return Field{Key: key, Type: ErrorType, Interface: v}
+	case []Field:
+		return Object(key, v...)
+	default:
+		return Field{Key: key, Type: AnyType, Interface: value}
+	}
+}
+
+// Value returns the value of the field as a plain Go value suitable for map based encoders.
+// Errors are returned as their message, objects as map[string]interface{} and namespaces as nil.
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case Int64Type:
+		return f.Integer
+	case Float64Type:
+		return f.Float
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case TimeType, AnyType:
+		return f.Interface
+	case ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			return err.Error()
+		}
+		return nil
+	case ObjectType:
+		fields, _ := f.Interface.([]Field)
+		return FieldsToMap(fields)
+	case ArrayType:
+		return f.Interface
+	default:
+		return nil
+	}
+}
+
+// FieldsToMap converts typed fields to a map, nesting fields that follow a Namespace
+// field under the namespace key. Later fields overwrite earlier fields with the same key.
+func FieldsToMap(fields []Field) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	current := result
+	for _, f := range fields {
+		if f.Type == NamespaceType {
+			nested := map[string]interface{}{}
+			current[f.Key] = nested
+			current = nested
+			continue
+		}
+		if f.Type == SkipType {
+			continue
+		}
+		current[f.Key] = f.Value()
+	}
+	return result
+}
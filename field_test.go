@@ -0,0 +1,78 @@
+package ectologger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldConstructors(t *testing.T) {
+	now := time.Now()
+	err := errors.New("test error")
+
+	testCases := []struct {
+		name      string
+		field     Field
+		fieldType FieldType
+		value     interface{}
+	}{
+		{"String", String("key", "value"), StringType, "value"},
+		{"Int64", Int64("key", 42), Int64Type, int64(42)},
+		{"Float64", Float64("key", 1.5), Float64Type, 1.5},
+		{"Bool", Bool("key", true), BoolType, true},
+		{"Duration", Duration("key", time.Second), DurationType, time.Second},
+		{"Time", Time("key", now), TimeType, now},
+		{"Err", Err(err), ErrorType, "test error"},
+		{"Array", Array("key", 1, "two"), ArrayType, []interface{}{1, "two"}},
+		{"Object", Object("key", String("nested", "value")), ObjectType, map[string]interface{}{"nested": "value"}},
+		{"Any", Any("key", struct{}{}), AnyType, struct{}{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.fieldType, tc.field.Type)
+			assert.Equal(t, tc.value, tc.field.Value())
+		})
+	}
+}
+
+func TestAnyPicksSpecificType(t *testing.T) {
+	assert.Equal(t, String("key", "value"), Any("key", "value"))
+	assert.Equal(t, Int64("key", 1), Any("key", 1))
+	assert.Equal(t, Float64("key", 1.5), Any("key", 1.5))
+	assert.Equal(t, Bool("key", false), Any("key", false))
+	assert.Equal(t, Duration("key", time.Minute), Any("key", time.Minute))
+}
+
+func TestErrNil(t *testing.T) {
+	assert.Equal(t, SkipType, Err(nil).Type)
+	assert.Empty(t, FieldsToMap([]Field{Err(nil)}))
+}
+
+func TestFieldsToMapNamespace(t *testing.T) {
+	fields := []Field{
+		String("service", "api"),
+		Namespace("request"),
+		String("id", "123"),
+		Int64("attempt", 2),
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"service": "api",
+		"request": map[string]interface{}{
+			"id":      "123",
+			"attempt": int64(2),
+		},
+	}, FieldsToMap(fields))
+}
+
+func TestEctoLogMessageFieldMap(t *testing.T) {
+	msg := EctoLogMessage{
+		Fields:      map[string]interface{}{"key": "map", "other": "value"},
+		TypedFields: []Field{String("key", "typed")},
+	}
+
+	assert.Equal(t, map[string]interface{}{"key": "typed", "other": "value"}, msg.FieldMap())
+}
@@ -0,0 +1,99 @@
+package gokitadapter
+
+import (
+	"fmt"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/go-kit/log"
+)
+
+// Keys with a special meaning in go-kit key-value pairs.
+const (
+	MessageKey = "msg"
+	LevelKey   = "level"
+	ErrorKey   = "err"
+)
+
+// Logger implements go-kit's log.Logger on top of an ectologger.Logger.
+// The "msg" value becomes the message, the "level" value (as written by go-kit's level
+// package) selects the level, defaulting to info, and an error under "err" becomes the
+// error of the message. All other pairs become fields.
+type Logger struct {
+	logger ectologger.Logger
+}
+
+var _ log.Logger = (*Logger)(nil)
+
+// New returns a go-kit log.Logger that logs through logger.
+func New(logger ectologger.Logger) *Logger {
+	return &Logger{logger: logger}
+}
+
+// Log logs the key-value pairs. As in go-kit, a trailing key without a value is logged
+// with log.ErrMissingValue as its value.
+func (l *Logger) Log(keyvals ...interface{}) error {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals[:len(keyvals):len(keyvals)], log.ErrMissingValue)
+	}
+
+	level, msg := ectologger.InfoLevel, ""
+	var err error
+	fields := make([]ectologger.Field, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		value := keyvals[i+1]
+
+		switch key {
+		case MessageKey:
+			msg = fmt.Sprint(value)
+			continue
+		case LevelKey:
+			if parsed, ok := parseLevel(value); ok {
+				level = parsed
+				continue
+			}
+		case ErrorKey:
+			if e, ok := value.(error); ok && e != log.ErrMissingValue {
+				err = e
+				continue
+			}
+		}
+		fields = append(fields, ectologger.Any(key, value))
+	}
+
+	logger := l.logger
+	if len(fields) > 0 {
+		logger = logger.WithTypedFields(fields...)
+	}
+	if err != nil {
+		logger = logger.WithError(err)
+	}
+	ectologger.LogAt(logger, level, msg)
+	return nil
+}
+
+// parseLevel maps a go-kit level value to an ectologger level.
+func parseLevel(value interface{}) (string, bool) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		return "", false
+	}
+
+	switch s {
+	case "debug":
+		return ectologger.DebugLevel, true
+	case "info":
+		return ectologger.InfoLevel, true
+	case "warn", "warning":
+		return ectologger.WarnLevel, true
+	case "error":
+		return ectologger.ErrorLevel, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,43 @@
+package gokitadapter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRecordingLogger(captured *[]ectologger.EctoLogMessage) *Logger {
+	return New(ectologger.NewEctoLogger(func(msg ectologger.EctoLogMessage) {
+		*captured = append(*captured, msg)
+	}))
+}
+
+func TestLoggerLog(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	err := errors.New("test error")
+	logger := log.With(newRecordingLogger(&captured), "component", "billing")
+
+	require.NoError(t, level.Warn(logger).Log("msg", "test message", "err", err, "count", 2))
+
+	require.Len(t, captured, 1)
+	assert.Equal(t, ectologger.WarnLevel, captured[0].Level)
+	assert.Equal(t, "test message", captured[0].Message)
+	assert.Equal(t, err, captured[0].Err)
+	assert.Equal(t, map[string]interface{}{"component": "billing", "count": int64(2)}, captured[0].FieldMap())
+}
+
+func TestLoggerLogDefaultsAndOddKeyvals(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	logger := newRecordingLogger(&captured)
+
+	require.NoError(t, logger.Log("msg", "test message", "level", "unknown", "dangling"))
+
+	require.Len(t, captured, 1)
+	assert.Equal(t, ectologger.InfoLevel, captured[0].Level)
+	assert.Equal(t, map[string]interface{}{"level": "unknown", "dangling": log.ErrMissingValue.Error()}, captured[0].FieldMap())
+}
@@ -0,0 +1,83 @@
+package grpcmiddleware
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gobusters/ectologger"
+	"google.golang.org/grpc/grpclog"
+)
+
+// exit terminates the process after a fatal log, replaced in tests.
+var exit = os.Exit
+
+// GRPCLogger is a grpclog.LoggerV2 that writes grpc-go's internal logs through an
+// ectologger.Logger. Install it with grpclog.SetLoggerV2 before any gRPC call is made.
+type GRPCLogger struct {
+	logger    ectologger.Logger
+	verbosity int
+}
+
+var _ grpclog.LoggerV2 = (*GRPCLogger)(nil)
+
+// NewGRPCLogger returns a GRPCLogger that logs through logger. verbosity is the highest
+// level V reports as enabled, grpc-go logs its most detailed messages at 2.
+func NewGRPCLogger(logger ectologger.Logger, verbosity int) *GRPCLogger {
+	return &GRPCLogger{logger: logger.WithField("system", "grpc"), verbosity: verbosity}
+}
+
+// Info logs args at the info level.
+func (l *GRPCLogger) Info(args ...interface{}) { l.logger.Info(fmt.Sprint(args...)) }
+
+// Infoln logs args at the info level.
+func (l *GRPCLogger) Infoln(args ...interface{}) { l.logger.Info(sprintln(args)) }
+
+// Infof logs a formatted message at the info level.
+func (l *GRPCLogger) Infof(format string, args ...interface{}) { l.logger.Infof(format, args...) }
+
+// Warning logs args at the warn level.
+func (l *GRPCLogger) Warning(args ...interface{}) { l.logger.Warn(fmt.Sprint(args...)) }
+
+// Warningln logs args at the warn level.
+func (l *GRPCLogger) Warningln(args ...interface{}) { l.logger.Warn(sprintln(args)) }
+
+// Warningf logs a formatted message at the warn level.
+func (l *GRPCLogger) Warningf(format string, args ...interface{}) { l.logger.Warnf(format, args...) }
+
+// Error logs args at the error level.
+func (l *GRPCLogger) Error(args ...interface{}) { l.logger.Error(fmt.Sprint(args...)) }
+
+// Errorln logs args at the error level.
+func (l *GRPCLogger) Errorln(args ...interface{}) { l.logger.Error(sprintln(args)) }
+
+// Errorf logs a formatted message at the error level.
+func (l *GRPCLogger) Errorf(format string, args ...interface{}) { l.logger.Errorf(format, args...) }
+
+// Fatal logs args at the fatal level and exits the process, as grpclog requires.
+func (l *GRPCLogger) Fatal(args ...interface{}) {
+	l.logger.Fatal(fmt.Sprint(args...))
+	exit(1)
+}
+
+// Fatalln logs args at the fatal level and exits the process, as grpclog requires.
+func (l *GRPCLogger) Fatalln(args ...interface{}) {
+	l.logger.Fatal(sprintln(args))
+	exit(1)
+}
+
+// Fatalf logs a formatted message at the fatal level and exits the process, as grpclog requires.
+func (l *GRPCLogger) Fatalf(format string, args ...interface{}) {
+	l.logger.Fatalf(format, args...)
+	exit(1)
+}
+
+// V reports whether verbosity level v is enabled.
+func (l *GRPCLogger) V(v int) bool {
+	return v <= l.verbosity
+}
+
+// sprintln formats args like fmt.Sprintln without the trailing newline.
+func sprintln(args []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
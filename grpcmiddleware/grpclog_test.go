@@ -0,0 +1,38 @@
+package grpcmiddleware
+
+import (
+	"testing"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCLogger(t *testing.T) {
+	rec := &recorder{}
+	logger := NewGRPCLogger(ectologger.NewEctoLogger(rec.log), 1)
+
+	exitCode := -1
+	defer func(orig func(int)) { exit = orig }(exit)
+	exit = func(code int) { exitCode = code }
+
+	logger.Info("connecting to ", "localhost")
+	logger.Warningln("transport", "closing")
+	logger.Errorf("dial failed: %d", 3)
+	logger.Fatal("cannot recover")
+
+	logged := rec.get()
+	require.Len(t, logged, 4)
+	assert.Equal(t, ectologger.InfoLevel, logged[0].Level)
+	assert.Equal(t, "connecting to localhost", logged[0].Message)
+	assert.Equal(t, "grpc", logged[0].FieldMap()["system"])
+	assert.Equal(t, ectologger.WarnLevel, logged[1].Level)
+	assert.Equal(t, "transport closing", logged[1].Message)
+	assert.Equal(t, ectologger.ErrorLevel, logged[2].Level)
+	assert.Equal(t, "dial failed: 3", logged[2].Message)
+	assert.Equal(t, ectologger.FatalLevel, logged[3].Level)
+	assert.Equal(t, 1, exitCode)
+
+	assert.True(t, logger.V(1))
+	assert.False(t, logger.V(2))
+}
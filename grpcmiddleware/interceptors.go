@@ -0,0 +1,185 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Options configures the interceptors.
+type Options struct {
+	// Level returns the level an RPC with the given status code is logged at. Defaults to DefaultLevel.
+	Level func(code codes.Code) string
+	// Skip reports whether the RPC to fullMethod is not logged, e.g. health checks.
+	// The request scoped logger is still added to the context of skipped RPCs.
+	Skip func(fullMethod string) bool
+}
+
+// DefaultLevel logs successful RPCs at info, errors caused by the caller at warn and
+// errors of the server or the network at error.
+func DefaultLevel(code codes.Code) string {
+	switch code {
+	case codes.OK:
+		return ectologger.InfoLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return ectologger.WarnLevel
+	default:
+		return ectologger.ErrorLevel
+	}
+}
+
+// UnaryServerInterceptor returns a server interceptor that adds a request scoped logger,
+// carrying the service, method and peer of the RPC, to the context of the handler, and logs
+// the RPC with its status code, duration and message sizes once the handler returns.
+// Handlers retrieve the logger with ectologger.FromContext.
+func UnaryServerInterceptor(logger ectologger.Logger, opts Options) grpc.UnaryServerInterceptor {
+	opts = withDefaults(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		reqLogger := requestLogger(ctx, logger, info.FullMethod)
+		resp, err := handler(ectologger.NewContext(ctx, reqLogger), req)
+		if !opts.Skip(info.FullMethod) {
+			logRPC(reqLogger, opts, "finished unary call", err, time.Since(start),
+				ectologger.Int64("grpc.request_size", int64(messageSize(req))),
+				ectologger.Int64("grpc.response_size", int64(messageSize(resp))),
+			)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that adds a request scoped logger to the
+// context of the stream, like UnaryServerInterceptor, and logs the RPC once the handler returns
+// with the number and total size of the requests and responses.
+func StreamServerInterceptor(logger ectologger.Logger, opts Options) grpc.StreamServerInterceptor {
+	opts = withDefaults(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		reqLogger := requestLogger(ss.Context(), logger, info.FullMethod)
+		stream := &serverStream{ServerStream: ss, ctx: ectologger.NewContext(ss.Context(), reqLogger)}
+		err := handler(srv, stream)
+		if !opts.Skip(info.FullMethod) {
+			logRPC(reqLogger, opts, "finished streaming call", err, time.Since(start), stream.counts.fields()...)
+		}
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that logs each outgoing RPC with its
+// service, method, target, status code, duration and message sizes.
+func UnaryClientInterceptor(logger ectologger.Logger, opts Options) grpc.UnaryClientInterceptor {
+	opts = withDefaults(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		if !opts.Skip(method) {
+			var size int
+			if err == nil {
+				size = messageSize(reply)
+			}
+			logRPC(clientLogger(ctx, logger, method, cc), opts, "finished client unary call", err, time.Since(start),
+				ectologger.Int64("grpc.request_size", int64(messageSize(req))),
+				ectologger.Int64("grpc.response_size", int64(size)),
+			)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor that logs each outgoing streaming RPC
+// once it ends, either with an error or with io.EOF from RecvMsg. Streams the caller abandons
+// without reading to the end are logged when their context is done; as grpc-go requires, the
+// caller must cancel the context of a stream it does not read to the end, or the stream is
+// never logged. Watching the context costs no goroutine.
+func StreamClientInterceptor(logger ectologger.Logger, opts Options) grpc.StreamClientInterceptor {
+	opts = withDefaults(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if opts.Skip(method) {
+			return cs, err
+		}
+		reqLogger := clientLogger(ctx, logger, method, cc)
+		if err != nil {
+			logRPC(reqLogger, opts, "finished client streaming call", err, time.Since(start))
+			return cs, err
+		}
+		stream := &clientStream{ClientStream: cs, serverStreams: desc.ServerStreams}
+		stream.finish = func(err error) {
+			logRPC(reqLogger, opts, "finished client streaming call", err, time.Since(start), stream.counts.fields()...)
+		}
+		stream.mu.Lock()
+		stream.stop = context.AfterFunc(ctx, func() { stream.end(ctx.Err()) })
+		stream.mu.Unlock()
+		return stream, nil
+	}
+}
+
+// withDefaults fills in the unset options.
+func withDefaults(opts Options) Options {
+	if opts.Level == nil {
+		opts.Level = DefaultLevel
+	}
+	if opts.Skip == nil {
+		opts.Skip = func(string) bool { return false }
+	}
+	return opts
+}
+
+// requestLogger returns the logger of an incoming RPC.
+func requestLogger(ctx context.Context, logger ectologger.Logger, fullMethod string) ectologger.Logger {
+	fields := methodFields(fullMethod)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, ectologger.String("peer.address", p.Addr.String()))
+	}
+	return logger.WithTypedFields(fields...).WithContext(ctx)
+}
+
+// clientLogger returns the logger of an outgoing RPC.
+func clientLogger(ctx context.Context, logger ectologger.Logger, fullMethod string, cc *grpc.ClientConn) ectologger.Logger {
+	fields := methodFields(fullMethod)
+	if cc != nil {
+		fields = append(fields, ectologger.String("grpc.target", cc.Target()))
+	}
+	return logger.WithTypedFields(fields...).WithContext(ctx)
+}
+
+// methodFields splits a full method name, "/package.Service/Method", into its service and method.
+func methodFields(fullMethod string) []ectologger.Field {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return []ectologger.Field{
+		ectologger.String("grpc.service", service),
+		ectologger.String("grpc.method", method),
+	}
+}
+
+// logRPC logs the completion of an RPC at the level of its status code.
+func logRPC(logger ectologger.Logger, opts Options, msg string, err error, elapsed time.Duration, fields ...ectologger.Field) {
+	code := status.Code(err)
+	logger = logger.WithTypedFields(append([]ectologger.Field{
+		ectologger.String("grpc.code", code.String()),
+		ectologger.Duration("grpc.duration", elapsed),
+	}, fields...)...)
+	if err != nil {
+		logger = logger.WithError(err)
+	}
+
+	ectologger.LogAt(logger, opts.Level(code), msg)
+}
+
+// messageSize returns the encoded size of a protobuf message, or zero for anything else.
+func messageSize(m interface{}) int {
+	if pm, ok := m.(proto.Message); ok {
+		return proto.Size(pm)
+	}
+	return 0
+}
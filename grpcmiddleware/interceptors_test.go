@@ -0,0 +1,227 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// recorder collects messages logged from server and client goroutines.
+type recorder struct {
+	mu       sync.Mutex
+	messages []ectologger.EctoLogMessage
+}
+
+func (r *recorder) log(msg ectologger.EctoLogMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+}
+
+func (r *recorder) get() []ectologger.EctoLogMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ectologger.EctoLogMessage(nil), r.messages...)
+}
+
+type testServer struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (testServer) UnaryCall(ctx context.Context, req *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+	logger, ok := ectologger.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "no logger in context")
+	}
+	logger.Info("handling")
+	if req.GetResponseSize() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "negative response size")
+	}
+	return &testpb.SimpleResponse{Payload: &testpb.Payload{Body: make([]byte, req.GetResponseSize())}}, nil
+}
+
+func (testServer) StreamingInputCall(stream testpb.TestService_StreamingInputCallServer) error {
+	if _, ok := ectologger.FromContext(stream.Context()); !ok {
+		return status.Error(codes.Internal, "no logger in context")
+	}
+	total := 0
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&testpb.StreamingInputCallResponse{AggregatedPayloadSize: int32(total)})
+		}
+		if err != nil {
+			return err
+		}
+		total += len(req.GetPayload().GetBody())
+	}
+}
+
+// newTestClient starts a server over bufconn with the server interceptors and returns a
+// client using the client interceptors.
+func newTestClient(t *testing.T, server, client *recorder, opts Options) testpb.TestServiceClient {
+	serverLogger := ectologger.NewEctoLogger(server.log)
+	clientLogger := ectologger.NewEctoLogger(client.log)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(serverLogger, opts)),
+		grpc.StreamInterceptor(StreamServerInterceptor(serverLogger, opts)),
+	)
+	testpb.RegisterTestServiceServer(srv, testServer{})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(clientLogger, opts)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(clientLogger, opts)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return testpb.NewTestServiceClient(conn)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	server, client := &recorder{}, &recorder{}
+	c := newTestClient(t, server, client, Options{})
+
+	req := &testpb.SimpleRequest{ResponseSize: 10}
+	_, err := c.UnaryCall(context.Background(), req)
+	require.NoError(t, err)
+
+	logged := server.get()
+	require.Len(t, logged, 2)
+	assert.Equal(t, "handling", logged[0].Message)
+	fields := logged[0].FieldMap()
+	assert.Equal(t, "grpc.testing.TestService", fields["grpc.service"])
+	assert.Equal(t, "UnaryCall", fields["grpc.method"])
+	assert.Equal(t, "bufconn", fields["peer.address"])
+
+	assert.Equal(t, ectologger.InfoLevel, logged[1].Level)
+	assert.Equal(t, "finished unary call", logged[1].Message)
+	fields = logged[1].FieldMap()
+	assert.Equal(t, "OK", fields["grpc.code"])
+	assert.Contains(t, fields, "grpc.duration")
+	assert.Equal(t, int64(2), fields["grpc.request_size"])
+	assert.Equal(t, int64(14), fields["grpc.response_size"])
+
+	logged = client.get()
+	require.Len(t, logged, 1)
+	assert.Equal(t, "finished client unary call", logged[0].Message)
+	fields = logged[0].FieldMap()
+	assert.Equal(t, "passthrough:///bufnet", fields["grpc.target"])
+	assert.Equal(t, "OK", fields["grpc.code"])
+	assert.Equal(t, int64(14), fields["grpc.response_size"])
+}
+
+func TestUnaryInterceptorsError(t *testing.T) {
+	server, client := &recorder{}, &recorder{}
+	c := newTestClient(t, server, client, Options{})
+
+	_, err := c.UnaryCall(context.Background(), &testpb.SimpleRequest{ResponseSize: -1})
+	require.Error(t, err)
+
+	for _, logged := range [][]ectologger.EctoLogMessage{server.get(), client.get()} {
+		last := logged[len(logged)-1]
+		assert.Equal(t, ectologger.WarnLevel, last.Level)
+		assert.Equal(t, "InvalidArgument", last.FieldMap()["grpc.code"])
+		assert.Equal(t, codes.InvalidArgument, status.Code(last.Err))
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	server, client := &recorder{}, &recorder{}
+	c := newTestClient(t, server, client, Options{})
+
+	stream, err := c.StreamingInputCall(context.Background())
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, stream.Send(&testpb.StreamingInputCallRequest{Payload: &testpb.Payload{Body: []byte("abc")}}))
+	}
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int32(9), resp.GetAggregatedPayloadSize())
+
+	logged := server.get()
+	require.Len(t, logged, 1)
+	assert.Equal(t, "finished streaming call", logged[0].Message)
+	fields := logged[0].FieldMap()
+	assert.Equal(t, "StreamingInputCall", fields["grpc.method"])
+	assert.Equal(t, "OK", fields["grpc.code"])
+	assert.Equal(t, int64(3), fields["grpc.requests"])
+	assert.Equal(t, int64(1), fields["grpc.responses"])
+
+	logged = client.get()
+	require.Len(t, logged, 1)
+	assert.Equal(t, "finished client streaming call", logged[0].Message)
+	fields = logged[0].FieldMap()
+	assert.Equal(t, int64(3), fields["grpc.requests"])
+	assert.Equal(t, int64(21), fields["grpc.request_size"])
+	assert.Equal(t, int64(1), fields["grpc.responses"])
+}
+
+func TestStreamClientInterceptorLogsAbandonedStream(t *testing.T) {
+	server, client := &recorder{}, &recorder{}
+	c := newTestClient(t, server, client, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.StreamingInputCall(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&testpb.StreamingInputCallRequest{Payload: &testpb.Payload{Body: []byte("abc")}}))
+	cancel()
+
+	require.Eventually(t, func() bool { return len(client.get()) == 1 }, time.Second, 5*time.Millisecond)
+	logged := client.get()[0]
+	assert.ErrorIs(t, logged.Err, context.Canceled)
+	assert.Equal(t, int64(1), logged.FieldMap()["grpc.requests"])
+}
+
+func TestClientStreamEndStopsWatchingContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ended := 0
+	stream := &clientStream{finish: func(error) { ended++ }}
+	stream.stop = context.AfterFunc(ctx, func() { stream.end(ctx.Err()) })
+
+	stream.end(nil)
+	assert.False(t, stream.stop(), "the context is still watched after the stream ended")
+	assert.Equal(t, 1, ended)
+}
+
+func TestInterceptorsSkip(t *testing.T) {
+	server, client := &recorder{}, &recorder{}
+	c := newTestClient(t, server, client, Options{
+		Skip: func(fullMethod string) bool { return fullMethod == testpb.TestService_UnaryCall_FullMethodName },
+	})
+
+	_, err := c.UnaryCall(context.Background(), &testpb.SimpleRequest{})
+	require.NoError(t, err)
+
+	// The handler still gets a logger, only the completion lines are skipped
+	logged := server.get()
+	require.Len(t, logged, 1)
+	assert.Equal(t, "handling", logged[0].Message)
+	assert.Empty(t, client.get())
+}
+
+func TestDefaultLevel(t *testing.T) {
+	assert.Equal(t, ectologger.InfoLevel, DefaultLevel(codes.OK))
+	assert.Equal(t, ectologger.WarnLevel, DefaultLevel(codes.NotFound))
+	assert.Equal(t, ectologger.ErrorLevel, DefaultLevel(codes.Internal))
+	assert.Equal(t, ectologger.ErrorLevel, DefaultLevel(codes.Unavailable))
+}
@@ -0,0 +1,128 @@
+package grpcmiddleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Gobusters/ectologger"
+	"google.golang.org/grpc"
+)
+
+// messageCounts tracks the requests and responses of a stream. Receiving and sending may
+// happen on different goroutines, so the counters are atomic.
+type messageCounts struct {
+	requests, requestSize   atomic.Int64
+	responses, responseSize atomic.Int64
+}
+
+// request counts a request message.
+func (c *messageCounts) request(m interface{}) {
+	c.requests.Add(1)
+	c.requestSize.Add(int64(messageSize(m)))
+}
+
+// response counts a response message.
+func (c *messageCounts) response(m interface{}) {
+	c.responses.Add(1)
+	c.responseSize.Add(int64(messageSize(m)))
+}
+
+// fields returns the counts as log fields.
+func (c *messageCounts) fields() []ectologger.Field {
+	return []ectologger.Field{
+		ectologger.Int64("grpc.requests", c.requests.Load()),
+		ectologger.Int64("grpc.request_size", c.requestSize.Load()),
+		ectologger.Int64("grpc.responses", c.responses.Load()),
+		ectologger.Int64("grpc.response_size", c.responseSize.Load()),
+	}
+}
+
+// serverStream wraps a grpc.ServerStream to replace its context and count its messages.
+type serverStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	counts messageCounts
+}
+
+// Context returns the context carrying the request scoped logger.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg receives a request and counts it.
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.counts.request(m)
+	}
+	return err
+}
+
+// SendMsg sends a response and counts it.
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.counts.response(m)
+	}
+	return err
+}
+
+// clientStream wraps a grpc.ClientStream to count its messages and log the RPC once it ends.
+type clientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	counts        messageCounts
+	finish        func(err error)
+	once          sync.Once
+
+	mu   sync.Mutex  // Guards stop, which may be set after the stream already ended
+	stop func() bool // Stops watching the context of the stream
+}
+
+// SendMsg sends a request and counts it. A failed send ends the stream.
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil {
+		// SendMsg returns io.EOF when the server ended the stream, the status is returned by RecvMsg
+		if !errors.Is(err, io.EOF) {
+			s.end(err)
+		}
+		return err
+	}
+	s.counts.request(m)
+	return nil
+}
+
+// RecvMsg receives a response and counts it. io.EOF, an error or the response of a stream
+// without server streaming ends the stream.
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.counts.response(m)
+		if !s.serverStreams {
+			// The single response of a unary response stream completes the RPC
+			s.end(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.end(nil)
+	default:
+		s.end(err)
+	}
+	return err
+}
+
+// end logs the RPC the first time it is called and stops watching the context.
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		if s.stop != nil {
+			s.stop()
+		}
+		s.mu.Unlock()
+		s.finish(err)
+	})
+}
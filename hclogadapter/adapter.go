@@ -0,0 +1,303 @@
+package hclogadapter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/hashicorp/go-hclog"
+)
+
+// NameKey is the key of the field holding the dotted name built by Named.
+const NameKey = ectologger.LoggerNameKey
+
+// Options configures a Logger created by New.
+type Options struct {
+	// Name is the initial name of the logger.
+	Name string
+	// Level is the initial level of the logger. hclog.NoLevel selects hclog.DefaultLevel.
+	Level hclog.Level
+}
+
+// Logger implements hclog.Logger on top of an ectologger.Logger, for Vault, Consul and
+// Nomad plugins. Names are set with ectologger.Logger.Named, so a LevelRegistry filters the
+// messages of named loggers. Loggers derived with With and Named share their level, like
+// hclog's own loggers.
+type Logger struct {
+	root    ectologger.Logger // The unnamed logger passed to New
+	logger  ectologger.Logger // root named name
+	name    string
+	implied []interface{}
+	level   *atomic.Int32
+}
+
+var _ hclog.Logger = (*Logger)(nil)
+
+// New returns an hclog.Logger that logs through logger.
+func New(logger ectologger.Logger, opts Options) *Logger {
+	if opts.Level == hclog.NoLevel {
+		opts.Level = hclog.DefaultLevel
+	}
+	level := &atomic.Int32{}
+	level.Store(int32(opts.Level))
+	l := &Logger{root: logger, logger: logger, name: opts.Name, level: level}
+	if opts.Name != "" {
+		l.logger = logger.Named(opts.Name)
+	}
+	return l
+}
+
+// Log logs msg at level with the given key-value pairs.
+func (l *Logger) Log(level hclog.Level, msg string, args ...interface{}) {
+	if level == hclog.Off || level < l.GetLevel() {
+		return
+	}
+
+	logger := l.logger
+	if fields := toFields(append(l.implied[:len(l.implied):len(l.implied)], args...)); len(fields) > 0 {
+		logger = logger.WithTypedFields(fields...)
+	}
+	ectologger.LogAt(logger, ectoLevel(level), msg)
+}
+
+// ectoLevel maps an hclog level to an ectologger level.
+func ectoLevel(level hclog.Level) string {
+	switch level {
+	case hclog.Trace:
+		return ectologger.TraceLevel
+	case hclog.Debug:
+		return ectologger.DebugLevel
+	case hclog.Warn:
+		return ectologger.WarnLevel
+	case hclog.Error:
+		return ectologger.ErrorLevel
+	default:
+		return ectologger.InfoLevel
+	}
+}
+
+// Trace logs msg at the trace level.
+func (l *Logger) Trace(msg string, args ...interface{}) { l.Log(hclog.Trace, msg, args...) }
+
+// Debug logs msg at the debug level.
+func (l *Logger) Debug(msg string, args ...interface{}) { l.Log(hclog.Debug, msg, args...) }
+
+// Info logs msg at the info level.
+func (l *Logger) Info(msg string, args ...interface{}) { l.Log(hclog.Info, msg, args...) }
+
+// Warn logs msg at the warn level.
+func (l *Logger) Warn(msg string, args ...interface{}) { l.Log(hclog.Warn, msg, args...) }
+
+// Error logs msg at the error level.
+func (l *Logger) Error(msg string, args ...interface{}) { l.Log(hclog.Error, msg, args...) }
+
+// IsTrace reports whether trace messages are logged.
+func (l *Logger) IsTrace() bool { return l.GetLevel() <= hclog.Trace }
+
+// IsDebug reports whether debug messages are logged.
+func (l *Logger) IsDebug() bool { return l.GetLevel() <= hclog.Debug }
+
+// IsInfo reports whether info messages are logged.
+func (l *Logger) IsInfo() bool { return l.GetLevel() <= hclog.Info }
+
+// IsWarn reports whether warn messages are logged.
+func (l *Logger) IsWarn() bool { return l.GetLevel() <= hclog.Warn }
+
+// IsError reports whether error messages are logged.
+func (l *Logger) IsError() bool { return l.GetLevel() <= hclog.Error }
+
+// ImpliedArgs returns the key-value pairs added with With.
+func (l *Logger) ImpliedArgs() []interface{} {
+	return l.implied
+}
+
+// With returns a Logger that adds the key-value pairs to every message. As in hclog, a key
+// given again replaces its earlier value, and a trailing value without a key is kept under
+// hclog.MissingKey.
+func (l *Logger) With(args ...interface{}) hclog.Logger {
+	var extra interface{}
+	if len(args)%2 != 0 {
+		extra = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	values := map[string]interface{}{}
+	keys := []string{}
+	for _, pairs := range [][]interface{}{l.implied, args} {
+		for i := 0; i+1 < len(pairs); i += 2 {
+			key := keyString(pairs[i])
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+			values[key] = pairs[i+1]
+		}
+	}
+	sort.Strings(keys)
+
+	c := *l
+	c.implied = make([]interface{}, 0, 2*len(keys)+2)
+	for _, key := range keys {
+		c.implied = append(c.implied, key, values[key])
+	}
+	if extra != nil {
+		c.implied = append(c.implied, hclog.MissingKey, extra)
+	}
+	return &c
+}
+
+// Name returns the name of the logger.
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// Named returns a Logger with name appended to the current name, separated by a dot.
+func (l *Logger) Named(name string) hclog.Logger {
+	c := *l
+	if c.name != "" {
+		c.name = l.name + "." + name
+	} else {
+		c.name = name
+	}
+	c.logger = l.logger.Named(name)
+	return &c
+}
+
+// ResetNamed returns a Logger with the given name, ignoring the current name.
+func (l *Logger) ResetNamed(name string) hclog.Logger {
+	c := *l
+	c.name = name
+	c.logger = l.root
+	if name != "" {
+		c.logger = l.root.Named(name)
+	}
+	return &c
+}
+
+// SetLevel changes the level of the logger and every logger derived from it.
+func (l *Logger) SetLevel(level hclog.Level) {
+	if level == hclog.NoLevel {
+		level = hclog.DefaultLevel
+	}
+	l.level.Store(int32(level))
+}
+
+// GetLevel returns the current level of the logger.
+func (l *Logger) GetLevel() hclog.Level {
+	return hclog.Level(l.level.Load())
+}
+
+// StandardLogger returns a *log.Logger whose output is logged through the logger.
+func (l *Logger) StandardLogger(opts *hclog.StandardLoggerOptions) *log.Logger {
+	return log.New(l.StandardWriter(opts), "", 0)
+}
+
+// StandardWriter returns an io.Writer logging each line written to it through the logger.
+// Level prefixes such as [DEBUG] or [ERR] are parsed when opts.InferLevels is set, and
+// stripped and replaced when opts.ForceLevel is set.
+func (l *Logger) StandardWriter(opts *hclog.StandardLoggerOptions) io.Writer {
+	if opts == nil {
+		opts = &hclog.StandardLoggerOptions{}
+	}
+	return &stdWriter{logger: l, opts: *opts}
+}
+
+// stdWriter logs each line written through the hclog adapter.
+type stdWriter struct {
+	logger *Logger
+	opts   hclog.StandardLoggerOptions
+}
+
+// levelPrefixes are the level prefixes recognized by InferLevels, as in hclog.
+var levelPrefixes = []struct {
+	prefix string
+	level  hclog.Level
+}{
+	{"[TRACE]", hclog.Trace},
+	{"[DEBUG]", hclog.Debug},
+	{"[INFO]", hclog.Info},
+	{"[WARN]", hclog.Warn},
+	{"[ERROR]", hclog.Error},
+	{"[ERR]", hclog.Error},
+}
+
+// Write logs every line in p.
+func (w *stdWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(string(bytes.TrimRight(p, "\r\n")), "\n") {
+		level, msg := w.parse(line)
+		w.logger.Log(level, msg)
+	}
+	return len(p), nil
+}
+
+// parse returns the level and the message of a line.
+func (w *stdWriter) parse(line string) (hclog.Level, string) {
+	if w.opts.ForceLevel == hclog.NoLevel && !w.opts.InferLevels {
+		return hclog.Info, line
+	}
+
+	level, msg := hclog.Info, line
+	for _, p := range levelPrefixes {
+		if idx := strings.Index(line, p.prefix); idx == 0 || (idx > 0 && w.opts.InferLevelsWithTimestamp) {
+			level, msg = p.level, strings.TrimSpace(line[idx+len(p.prefix):])
+			break
+		}
+	}
+	if w.opts.ForceLevel != hclog.NoLevel {
+		level = w.opts.ForceLevel
+	}
+	return level, msg
+}
+
+// toFields converts hclog key-value pairs to typed fields. A trailing value without a key is
+// kept under hclog.MissingKey, and hclog's formatting types are rendered as hclog does.
+func toFields(args []interface{}) []ectologger.Field {
+	if len(args)%2 != 0 {
+		extra := args[len(args)-1]
+		args = append(args[:len(args)-1:len(args)-1], hclog.MissingKey, extra)
+	}
+
+	fields := make([]ectologger.Field, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		fields = append(fields, ectologger.Any(keyString(args[i]), formatValue(args[i+1])))
+	}
+	return fields
+}
+
+// keyString returns a key as a string.
+func keyString(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+// formatValue renders hclog's formatting types.
+func formatValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case hclog.Format:
+		if len(v) == 0 {
+			return ""
+		}
+		format, ok := v[0].(string)
+		if !ok {
+			return fmt.Sprint(v...)
+		}
+		return fmt.Sprintf(format, v[1:]...)
+	case hclog.Hex:
+		return "0x" + strconv.FormatInt(int64(v), 16)
+	case hclog.Octal:
+		return "0" + strconv.FormatInt(int64(v), 8)
+	case hclog.Binary:
+		return "0b" + strconv.FormatInt(int64(v), 2)
+	case hclog.Quote:
+		return strconv.Quote(string(v))
+	default:
+		return value
+	}
+}
@@ -0,0 +1,102 @@
+package hclogadapter
+
+import (
+	"testing"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRecordingLogger(captured *[]ectologger.EctoLogMessage, opts Options) *Logger {
+	return New(ectologger.NewEctoLogger(func(msg ectologger.EctoLogMessage) {
+		*captured = append(*captured, msg)
+	}), opts)
+}
+
+func TestLoggerLevelsAndNames(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	logger := newRecordingLogger(&captured, Options{Name: "vault"})
+
+	named := logger.Named("plugin").Named("kv")
+	named.Debug("filtered")
+	named.Info("info", "path", "secret/")
+	named.ResetNamed("standalone").Error("error")
+
+	require.Len(t, captured, 2)
+	assert.Equal(t, ectologger.InfoLevel, captured[0].Level)
+	assert.Equal(t, map[string]interface{}{NameKey: "vault.plugin.kv", "path": "secret/"}, captured[0].FieldMap())
+	assert.Equal(t, ectologger.ErrorLevel, captured[1].Level)
+	assert.Equal(t, "standalone", captured[1].FieldMap()[NameKey])
+	assert.Equal(t, "vault.plugin.kv", named.Name())
+}
+
+func TestLoggerSharedLevel(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	logger := newRecordingLogger(&captured, Options{Level: hclog.Warn})
+	child := logger.Named("child")
+
+	assert.False(t, child.IsInfo())
+	logger.SetLevel(hclog.Trace)
+	assert.True(t, child.IsTrace())
+	assert.Equal(t, hclog.Trace, child.GetLevel())
+
+	child.Trace("trace")
+	require.Len(t, captured, 1)
+	assert.Equal(t, ectologger.TraceLevel, captured[0].Level)
+}
+
+func TestLoggerNamesFollowLevelRegistry(t *testing.T) {
+	registry, err := ectologger.NewLevelRegistry("vault.plugin=error,standalone=debug")
+	require.NoError(t, err)
+	var captured []ectologger.EctoLogMessage
+	logger := New(ectologger.NewEctoLogger(func(msg ectologger.EctoLogMessage) {
+		captured = append(captured, msg)
+	}, ectologger.WithLevelRegistry(registry)), Options{Name: "vault", Level: hclog.Debug})
+
+	plugin := logger.Named("plugin")
+	plugin.Warn("dropped by the registry")
+	plugin.Error("plugin error")
+	plugin.ResetNamed("standalone").Debug("standalone debug")
+	logger.Debug("vault debug")
+
+	require.Len(t, captured, 3)
+	assert.Equal(t, "plugin error", captured[0].Message)
+	assert.Equal(t, "vault.plugin", captured[0].FieldMap()[NameKey])
+	assert.Equal(t, "standalone debug", captured[1].Message)
+	assert.Equal(t, "standalone", captured[1].FieldMap()[NameKey])
+	assert.Equal(t, "vault debug", captured[2].Message)
+}
+
+func TestLoggerWithAndOddArgs(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	logger := newRecordingLogger(&captured, Options{})
+
+	with := logger.With("b", 1, "a", 2).With("b", 3, "dangling")
+	assert.Equal(t, []interface{}{"a", 2, "b", 3, hclog.MissingKey, "dangling"}, with.ImpliedArgs())
+
+	logger.Info("test message", "key", hclog.Fmt("%d%%", 50), "hex", hclog.Hex(255), "extra")
+
+	require.Len(t, captured, 1)
+	assert.Equal(t, map[string]interface{}{"key": "50%", "hex": "0xff", hclog.MissingKey: "extra"}, captured[0].FieldMap())
+}
+
+func TestStandardLogger(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	logger := newRecordingLogger(&captured, Options{Level: hclog.Trace})
+
+	std := logger.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true})
+	std.Print("[DEBUG] debug line")
+	std.Print("[ERR] error line")
+	std.Print("plain line")
+
+	forced := logger.StandardLogger(&hclog.StandardLoggerOptions{ForceLevel: hclog.Warn})
+	forced.Print("[INFO] forced line")
+
+	require.Len(t, captured, 4)
+	assert.Equal(t, []string{ectologger.DebugLevel, ectologger.ErrorLevel, ectologger.InfoLevel, ectologger.WarnLevel},
+		[]string{captured[0].Level, captured[1].Level, captured[2].Level, captured[3].Level})
+	assert.Equal(t, []string{"debug line", "error line", "plain line", "forced line"},
+		[]string{captured[0].Message, captured[1].Message, captured[2].Message, captured[3].Message})
+}
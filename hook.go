@@ -0,0 +1,226 @@
+package ectologger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultHookQueueSize is the number of asynchronous hook calls a Hooks queues by default.
+const defaultHookQueueSize = 1000
+
+// Hook is notified of messages at the levels it returns from Levels, independently of the
+// log function the message is written to. It can be used to send errors to an alerting endpoint.
+type Hook interface {
+	// Levels returns the levels the hook fires for.
+	Levels() []string
+
+	// Fire is called with each message at one of the hook's levels.
+	Fire(msg EctoLogMessage) error
+}
+
+// hookFunc adapts a function to the Hook interface.
+type hookFunc struct {
+	levels []string
+	fire   func(msg EctoLogMessage) error
+}
+
+func (h hookFunc) Levels() []string              { return h.levels }
+func (h hookFunc) Fire(msg EctoLogMessage) error { return h.fire(msg) }
+
+// NewHook returns a Hook that calls fire for messages at the given levels.
+func NewHook(levels []string, fire func(msg EctoLogMessage) error) Hook {
+	return hookFunc{levels: levels, fire: fire}
+}
+
+// registeredHook is a hook together with how it is executed.
+type registeredHook struct {
+	hook  Hook
+	async bool
+}
+
+// hookCall is a queued call of an asynchronous hook. A call with flushed set carries no hook;
+// the worker closes flushed once every call queued before it has returned.
+type hookCall struct {
+	hook    Hook
+	msg     EctoLogMessage
+	flushed chan struct{}
+}
+
+// HooksOptions configures a Hooks registry.
+type HooksOptions struct {
+	// QueueSize bounds the asynchronous hook calls waiting for the worker. Calls beyond it are
+	// dropped and counted, see Hooks.Dropped. Defaults to 1000.
+	QueueSize int
+}
+
+// Hooks is a registry of hooks by level. Hooks can be added while loggers using it are live.
+// Errors returned by hooks and panics inside them are recovered and passed to the error
+// handler set with SetErrorHandler instead of reaching the logging call site.
+//
+// Asynchronous hooks are called one at a time by a single worker goroutine, started with the
+// first asynchronous call, from a bounded queue. Flush waits for the queue to drain and Close
+// stops the worker once it has.
+type Hooks struct {
+	mu      sync.RWMutex
+	byLevel map[string][]registeredHook
+
+	queueMu sync.RWMutex // Held for reading while queueing and for writing by Close
+	queue   chan hookCall
+	closed  bool
+	start   sync.Once
+	done    chan struct{} // Closed when the worker returns
+	dropped atomic.Int64
+}
+
+// NewHooks returns an empty hook registry with the default options.
+func NewHooks() *Hooks {
+	return NewHooksWithOptions(HooksOptions{})
+}
+
+// NewHooksWithOptions returns an empty hook registry configured by opts.
+func NewHooksWithOptions(opts HooksOptions) *Hooks {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultHookQueueSize
+	}
+	return &Hooks{
+		byLevel: map[string][]registeredHook{},
+		queue:   make(chan hookCall, opts.QueueSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// Add registers a hook that fires synchronously, before the message is passed on.
+func (h *Hooks) Add(hook Hook) {
+	h.add(registeredHook{hook: hook})
+}
+
+// AddAsync registers a hook that fires on the worker goroutine so a slow hook does not delay
+// logging. When the queue is full the call is dropped rather than blocking the caller.
+func (h *Hooks) AddAsync(hook Hook) {
+	h.add(registeredHook{hook: hook, async: true})
+}
+
+// add registers hook under each of its levels.
+func (h *Hooks) add(hook registeredHook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, level := range hook.hook.Levels() {
+		h.byLevel[level] = append(h.byLevel[level], hook)
+	}
+}
+
+// Dropped returns the number of asynchronous hook calls dropped because the queue was full
+// or the registry was closed.
+func (h *Hooks) Dropped() int64 {
+	return h.dropped.Load()
+}
+
+// Flush blocks until every asynchronous hook call queued so far has returned.
+func (h *Hooks) Flush() {
+	h.queueMu.RLock()
+	if h.closed {
+		h.queueMu.RUnlock()
+		return
+	}
+	h.start.Do(h.startWorker)
+	flushed := make(chan struct{})
+	h.queue <- hookCall{flushed: flushed}
+	h.queueMu.RUnlock()
+
+	<-flushed
+}
+
+// Close waits for the queued asynchronous hook calls to return and stops the worker.
+// Asynchronous hooks firing after Close are dropped; synchronous hooks keep firing.
+func (h *Hooks) Close() {
+	h.queueMu.Lock()
+	if h.closed {
+		h.queueMu.Unlock()
+		return
+	}
+	h.closed = true
+	h.start.Do(h.startWorker)
+	close(h.queue)
+	h.queueMu.Unlock()
+
+	<-h.done
+}
+
+// Fire calls every hook registered for the level of msg.
+func (h *Hooks) Fire(msg EctoLogMessage) {
+	h.mu.RLock()
+	hooks := h.byLevel[msg.Level]
+	h.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if hook.async {
+			h.enqueue(hookCall{hook: hook.hook, msg: msg})
+			continue
+		}
+		fireHook(hook.hook, msg)
+	}
+}
+
+// enqueue queues call for the worker, dropping it when the queue is full or closed.
+func (h *Hooks) enqueue(call hookCall) {
+	h.queueMu.RLock()
+	defer h.queueMu.RUnlock()
+	if h.closed {
+		h.dropped.Add(1)
+		return
+	}
+	h.start.Do(h.startWorker)
+	select {
+	case h.queue <- call:
+	default:
+		h.dropped.Add(1)
+	}
+}
+
+// startWorker starts the goroutine calling the queued asynchronous hooks.
+func (h *Hooks) startWorker() {
+	go func() {
+		defer close(h.done)
+		for call := range h.queue {
+			if call.flushed != nil {
+				close(call.flushed)
+				continue
+			}
+			fireHook(call.hook, call.msg)
+		}
+	}()
+}
+
+// Processor returns a processor that fires the hooks for each message and then passes it on.
+// Messages with hooks are resolved first, so hooks and the log function share one resolution.
+func (h *Hooks) Processor() Processor {
+	return func(msg EctoLogMessage, next EctoLogFunc) {
+		h.mu.RLock()
+		hasHooks := len(h.byLevel[msg.Level]) > 0
+		h.mu.RUnlock()
+
+		if hasHooks {
+			msg = msg.Resolve()
+			h.Fire(msg)
+		}
+		next(msg)
+	}
+}
+
+// WithHooks fires the hooks registered in hooks for every message logged through the logger.
+func WithHooks(hooks *Hooks) Option {
+	return WithProcessors(hooks.Processor())
+}
+
+// fireHook calls hook, reporting a returned error or a panic to the error handler.
+func fireHook(hook Hook, msg EctoLogMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportError(fmt.Errorf("ectologger: hook %T panicked: %v", hook, r))
+		}
+	}()
+	if err := hook.Fire(msg); err != nil {
+		reportError(fmt.Errorf("ectologger: hook %T failed: %w", hook, err))
+	}
+}
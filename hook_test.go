@@ -0,0 +1,154 @@
+package ectologger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksFireForTheirLevels(t *testing.T) {
+	rec := &messageRecorder{}
+	var fired []string
+	hooks := NewHooks()
+	hooks.Add(NewHook([]string{ErrorLevel, FatalLevel}, func(msg EctoLogMessage) error {
+		fired = append(fired, msg.Message)
+		return nil
+	}))
+	logger := NewEctoLogger(rec.Log, WithHooks(hooks))
+
+	logger.Info("info")
+	logger.Error("error")
+	logger.Fatal("fatal")
+
+	assert.Equal(t, []string{"error", "fatal"}, fired)
+	assert.Equal(t, []string{"info", "error", "fatal"}, rec.Messages())
+}
+
+func TestAsyncHooks(t *testing.T) {
+	var mu sync.Mutex
+	var fired []string
+	hooks := NewHooks()
+	hooks.AddAsync(NewHook([]string{ErrorLevel}, func(msg EctoLogMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		fired = append(fired, msg.Message)
+		return nil
+	}))
+	logger := NewEctoLogger(func(EctoLogMessage) {}, WithHooks(hooks))
+
+	logger.Error("first")
+	logger.Error("second")
+	hooks.Flush()
+
+	assert.ElementsMatch(t, []string{"first", "second"}, fired)
+}
+
+func TestHookErrorsAndPanicsAreReported(t *testing.T) {
+	var mu sync.Mutex
+	var reported []error
+	SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	})
+	defer SetErrorHandler(nil)
+
+	rec := &messageRecorder{}
+	hooks := NewHooks()
+	hooks.Add(NewHook([]string{ErrorLevel}, func(EctoLogMessage) error { return errors.New("endpoint down") }))
+	hooks.Add(NewHook([]string{ErrorLevel}, func(EctoLogMessage) error { panic("boom") }))
+	hooks.AddAsync(NewHook([]string{ErrorLevel}, func(EctoLogMessage) error { panic("async boom") }))
+	logger := NewEctoLogger(rec.Log, WithHooks(hooks))
+
+	assert.NotPanics(t, func() { logger.Error("test message") })
+	hooks.Flush()
+
+	assert.Equal(t, []string{"test message"}, rec.Messages())
+	require.Len(t, reported, 3)
+	assert.Contains(t, reported[0].Error(), "endpoint down")
+	assert.Contains(t, reported[1].Error(), "panicked: boom")
+	assert.Contains(t, reported[2].Error(), "panicked: async boom")
+}
+
+func TestHooksShareResolution(t *testing.T) {
+	valuer := &countingValuer{}
+	hooks := NewHooks()
+	hooks.Add(NewHook([]string{ErrorLevel}, func(msg EctoLogMessage) error {
+		msg.Resolve()
+		return nil
+	}))
+	logger := NewEctoLogger(func(msg EctoLogMessage) { msg.Resolve() }, WithHooks(hooks))
+
+	logger.WithField("lazy", valuer).Error("test message")
+
+	assert.Equal(t, 1, valuer.calls)
+}
+
+func TestHooksResolveFieldsAddedLater(t *testing.T) {
+	valuer := &countingValuer{}
+	hooks := NewHooks()
+	hooks.Add(NewHook([]string{ErrorLevel}, func(EctoLogMessage) error { return nil }))
+	rec := &messageRecorder{}
+	logger := NewEctoLogger(func(msg EctoLogMessage) { rec.Log(msg.Resolve()) },
+		WithHooks(hooks), WithProcessors(AddFields(Any("lazy", valuer))))
+
+	logger.Error("test message")
+
+	require.Len(t, rec.messages, 1)
+	assert.Equal(t, "resolved", rec.messages[0].FieldMap()["lazy"])
+	assert.Equal(t, 1, valuer.calls)
+}
+
+func TestAsyncHooksQueueIsBounded(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var fired atomic.Int64
+	hooks := NewHooksWithOptions(HooksOptions{QueueSize: 2})
+	hooks.AddAsync(NewHook([]string{ErrorLevel}, func(EctoLogMessage) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		fired.Add(1)
+		return nil
+	}))
+
+	hooks.Fire(EctoLogMessage{Level: ErrorLevel, Message: "blocks the worker"})
+	<-started
+	for i := 0; i < 4; i++ {
+		hooks.Fire(EctoLogMessage{Level: ErrorLevel, Message: "queued"})
+	}
+	assert.Equal(t, int64(2), hooks.Dropped())
+
+	close(release)
+	hooks.Flush()
+	assert.Equal(t, int64(3), fired.Load())
+}
+
+func TestHooksClose(t *testing.T) {
+	var fired atomic.Int64
+	hooks := NewHooks()
+	hooks.AddAsync(NewHook([]string{ErrorLevel}, func(EctoLogMessage) error {
+		time.Sleep(time.Millisecond)
+		fired.Add(1)
+		return nil
+	}))
+
+	for i := 0; i < 5; i++ {
+		hooks.Fire(EctoLogMessage{Level: ErrorLevel})
+	}
+	hooks.Close()
+	assert.Equal(t, int64(5), fired.Load())
+
+	hooks.Fire(EctoLogMessage{Level: ErrorLevel})
+	hooks.Flush()
+	hooks.Close()
+	assert.Equal(t, int64(5), fired.Load())
+	assert.Equal(t, int64(1), hooks.Dropped())
+}
@@ -0,0 +1,182 @@
+package httpmiddleware
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Gobusters/ectologger"
+)
+
+// defaultMaxBodyBytes is the number of bytes of each body captured by default.
+const defaultMaxBodyBytes = 4096
+
+// DefaultCaptureContentTypes are the media types whose bodies are captured by default.
+var DefaultCaptureContentTypes = []string{
+	"application/json",
+	"application/*+json",
+	"application/xml",
+	"application/*+xml",
+	"application/x-www-form-urlencoded",
+	"text/*",
+}
+
+// DefaultRedactHeaders are the headers whose values are redacted by default.
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// BodyCapture configures logging of request and response bodies and headers, for debugging
+// integrations. Bodies are captured as they are read or written, so they are never buffered
+// beyond MaxBytes and streaming requests and responses keep working.
+type BodyCapture struct {
+	// MaxBytes is the number of bytes of each body logged. Longer bodies are cut off and
+	// flagged as truncated. Defaults to 4096.
+	MaxBytes int
+	// ContentTypes are the media types whose bodies are logged, as path.Match patterns such
+	// as "text/*". Defaults to DefaultCaptureContentTypes.
+	ContentTypes []string
+	// Headers logs the request and response headers.
+	Headers bool
+	// RedactHeaders are the headers logged as ectologger.RedactedValue. Defaults to DefaultRedactHeaders.
+	RedactHeaders []string
+}
+
+// withDefaults fills in the unset options.
+func (c BodyCapture) withDefaults() *BodyCapture {
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = defaultMaxBodyBytes
+	}
+	if c.ContentTypes == nil {
+		c.ContentTypes = DefaultCaptureContentTypes
+	}
+	if c.RedactHeaders == nil {
+		c.RedactHeaders = DefaultRedactHeaders
+	}
+	redact := make([]string, len(c.RedactHeaders))
+	for i, h := range c.RedactHeaders {
+		redact[i] = http.CanonicalHeaderKey(h)
+	}
+	c.RedactHeaders = redact
+	return &c
+}
+
+// captures reports whether bodies of contentType are logged.
+func (c *BodyCapture) captures(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range c.ContentTypes {
+		if ok, _ := path.Match(pattern, mediaType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns the log fields of a captured message, named prefix.headers, prefix.body
+// and prefix.body_truncated. body is nil when the body was not captured.
+func (c *BodyCapture) fields(prefix string, header http.Header, body *bodyBuffer) []ectologger.Field {
+	var fields []ectologger.Field
+	if c.Headers {
+		fields = append(fields, headerField(prefix+".headers", header, c.RedactHeaders))
+	}
+	if body != nil {
+		captured, truncated := body.get()
+		fields = append(fields, ectologger.String(prefix+".body", captured))
+		if truncated {
+			fields = append(fields, ectologger.Bool(prefix+".body_truncated", true))
+		}
+	}
+	return fields
+}
+
+// headerField returns header as an object field with the values of redact replaced.
+func headerField(key string, header http.Header, redact []string) ectologger.Field {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]ectologger.Field, len(names))
+	for i, name := range names {
+		values[i] = ectologger.String(name, strings.Join(header[name], ", "))
+	}
+	return ectologger.Object(key, ectologger.RedactFields(values, redact...)...)
+}
+
+// bodyBuffer holds the first bytes of a body. The transport may still be reading a request
+// body when the response is logged, so it is safe for concurrent use.
+type bodyBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+// newBodyBuffer returns a bodyBuffer holding up to limit bytes.
+func newBodyBuffer(limit int) *bodyBuffer {
+	return &bodyBuffer{max: limit}
+}
+
+// Write keeps as much of p as fits and never fails, so it can be used with io.TeeReader.
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.max - len(b.buf); room < len(p) {
+		b.buf = append(b.buf, p[:max(room, 0)]...)
+		b.truncated = b.truncated || len(p) > 0
+	} else {
+		b.buf = append(b.buf, p...)
+	}
+	return len(p), nil
+}
+
+// get returns the captured bytes and whether the body was longer.
+func (b *bodyBuffer) get() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf), b.truncated
+}
+
+// captureBody is a request or response body that counts the bytes read from it and copies
+// them into a bodyBuffer.
+type captureBody struct {
+	io.ReadCloser
+	buf     *bodyBuffer  // Nil when the body is only counted
+	read    atomic.Int64 // Close may be called while another goroutine reads
+	onClose func()
+	once    sync.Once
+}
+
+// Read reads from the body and captures the bytes read. The end of the body counts as closing it.
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read.Add(int64(n))
+	if b.buf != nil {
+		_, _ = b.buf.Write(p[:n])
+	}
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+// Close closes the body.
+func (b *captureBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	return err
+}
+
+// done calls onClose once.
+func (b *captureBody) done() {
+	if b.onClose != nil {
+		b.once.Do(b.onClose)
+	}
+}
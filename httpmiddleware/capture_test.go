@@ -0,0 +1,78 @@
+package httpmiddleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyCaptureContentTypes(t *testing.T) {
+	c := BodyCapture{}.withDefaults()
+	assert.True(t, c.captures("application/json; charset=utf-8"))
+	assert.True(t, c.captures("application/problem+json"))
+	assert.True(t, c.captures("text/plain"))
+	assert.False(t, c.captures("image/png"))
+	assert.False(t, c.captures(""))
+}
+
+func TestBodyBufferTruncates(t *testing.T) {
+	b := newBodyBuffer(5)
+	n, err := b.Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	_, _ = b.Write([]byte("defg"))
+
+	captured, truncated := b.get()
+	assert.Equal(t, "abcde", captured)
+	assert.True(t, truncated)
+}
+
+func TestMiddlewareBodyCapture(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"name":"jane","role":"admin"}`, string(body), "the handler reads the whole body")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		_, _ = w.Write([]byte("created user jane"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"jane","role":"admin"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	_, logged := serve(handler, Options{Body: &BodyCapture{MaxBytes: 16, Headers: true}}, req)
+
+	require.Len(t, logged, 1)
+	fields := logged[0].FieldMap()
+	assert.Equal(t, `{"name":"jane","`, fields["http.request.body"])
+	assert.Equal(t, true, fields["http.request.body_truncated"])
+	assert.Equal(t, "created user jan", fields["http.response.body"])
+	assert.Equal(t, map[string]interface{}{
+		"Authorization": ectologger.RedactedValue,
+		"Content-Type":  "application/json",
+	}, fields["http.request.headers"])
+	assert.Equal(t, ectologger.RedactedValue, fields["http.response.headers"].(map[string]interface{})["Set-Cookie"])
+}
+
+func TestMiddlewareBodyCaptureSkipsContentTypes(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte{0, 1, 2})
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/upload", strings.NewReader("binary"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	_, logged := serve(handler, Options{Body: &BodyCapture{}}, req)
+
+	require.Len(t, logged, 1)
+	fields := logged[0].FieldMap()
+	assert.NotContains(t, fields, "http.request.body")
+	assert.NotContains(t, fields, "http.response.body")
+	assert.NotContains(t, fields, "http.request.headers")
+}
@@ -0,0 +1,101 @@
+package httpmiddleware
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/Gobusters/ectologger"
+)
+
+// combinedTimeFormat is the timestamp format of the Apache log formats.
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// CombinedAccessLog returns an AccessLogWriter that writes each request to w in the Apache
+// combined log format, for tools that parse web server access logs:
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"
+//
+// Panics are still logged through the request scoped logger, as the format cannot hold them.
+func CombinedAccessLog(w io.Writer) AccessLogWriter {
+	var mu sync.Mutex
+	return func(logger ectologger.Logger, level string, entry AccessLog) {
+		if entry.Panic != nil {
+			StructuredAccessLog(logger, level, entry)
+		}
+
+		line := appendCombined(nil, entry)
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := w.Write(line); err != nil {
+			logger.WithError(err).Error("failed to write access log")
+		}
+	}
+}
+
+// appendCombined appends entry in the Apache combined log format, followed by a newline, to b.
+func appendCombined(b []byte, entry AccessLog) []byte {
+	r := entry.Request
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	user := "-"
+	if r.URL.User != nil && r.URL.User.Username() != "" {
+		user = r.URL.User.Username()
+	} else if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+
+	b = appendField(b, host)
+	b = append(b, " - "...)
+	b = appendField(b, user)
+	b = append(b, " ["...)
+	b = entry.Start.AppendFormat(b, combinedTimeFormat)
+	b = append(b, "] \""...)
+	b = appendEscaped(b, r.Method+" "+uri+" "+r.Proto)
+	b = append(b, "\" "...)
+	b = strconv.AppendInt(b, int64(entry.Status), 10)
+	b = append(b, ' ')
+	if entry.Bytes == 0 {
+		b = append(b, '-')
+	} else {
+		b = strconv.AppendInt(b, entry.Bytes, 10)
+	}
+	b = append(b, " \""...)
+	b = appendEscaped(b, r.Referer())
+	b = append(b, "\" \""...)
+	b = appendEscaped(b, r.UserAgent())
+	return append(b, "\"\n"...)
+}
+
+// appendField appends an unquoted field, "-" when it is empty.
+func appendField(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, '-')
+	}
+	return appendEscaped(b, s)
+}
+
+// appendEscaped appends s with quotes, backslashes and control characters escaped the way
+// Apache does, so request data cannot forge log lines.
+func appendEscaped(b []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20 || c == 0x7f:
+			b = append(b, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
@@ -0,0 +1,49 @@
+package httpmiddleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendCombined(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/apache_pb.gif?a=1", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.SetBasicAuth("frank", "secret")
+	req.Header.Set("Referer", "http://www.example.com/start.html")
+	req.Header.Set("User-Agent", `Mozilla/4.08 "quoted"`+"\n")
+
+	entry := AccessLog{
+		Request: req,
+		Status:  200,
+		Bytes:   2326,
+		Start:   time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60)),
+	}
+	assert.Equal(t,
+		`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.1" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 \"quoted\"\x0a"`+"\n",
+		string(appendCombined(nil, entry)))
+
+	entry.Request = httptest.NewRequest(http.MethodHead, "/", nil)
+	entry.Bytes = 0
+	assert.Equal(t,
+		`192.0.2.1 - - [10/Oct/2000:13:55:36 -0700] "HEAD / HTTP/1.1" 200 - "" ""`+"\n",
+		string(appendCombined(nil, entry)))
+}
+
+func TestCombinedAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	rec := &recorder{}
+	handler := Middleware(ectologger.NewEctoLogger(rec.log), Options{AccessLog: CombinedAccessLog(&buf)})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Contains(t, buf.String(), `"GET /missing HTTP/1.1" 404 19 "" ""`)
+	assert.Empty(t, rec.get())
+}
@@ -0,0 +1,265 @@
+package httpmiddleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+)
+
+// DefaultRequestIDHeader is the header the request ID is read from and echoed in by default.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// requestIDKey is the context key of the request ID.
+type requestIDKey struct{}
+
+// Options configures the middleware.
+type Options struct {
+	// RequestIDHeader is the header the request ID is read from. When the request has none a
+	// new ID is generated. The ID is set on the response under the same header. Defaults to X-Request-Id.
+	RequestIDHeader string
+	// NewRequestID generates request IDs. Defaults to 16 random bytes in hex.
+	NewRequestID func() string
+	// Level returns the level the access log line of a response with status is logged at. Defaults to DefaultLevel.
+	Level func(status int) string
+	// Skip reports whether the access log line of r is left out, e.g. health checks.
+	// The request scoped logger is still added to the context of skipped requests.
+	Skip func(r *http.Request) bool
+	// AccessLog writes the access log line of each completed request. Defaults to StructuredAccessLog.
+	AccessLog AccessLogWriter
+	// Body enables logging of request and response bodies and headers with the access log
+	// line. The fields are added to the logger passed to AccessLog. Disabled when nil.
+	Body *BodyCapture
+}
+
+// AccessLog describes a completed request.
+type AccessLog struct {
+	Request   *http.Request
+	RequestID string
+	// Status is the response status code, 200 when the handler did not write one.
+	Status int
+	// Bytes is the number of body bytes written.
+	Bytes    int64
+	Start    time.Time
+	Duration time.Duration
+	// Panic is the value the handler panicked with, or nil.
+	Panic interface{}
+}
+
+// AccessLogWriter writes the access log line of a completed request. logger is the request
+// scoped logger and level the level selected for the response.
+type AccessLogWriter func(logger ectologger.Logger, level string, entry AccessLog)
+
+// DefaultLevel logs server errors at error, client errors at warn and everything else at info.
+func DefaultLevel(status int) string {
+	switch {
+	case status >= 500:
+		return ectologger.ErrorLevel
+	case status >= 400:
+		return ectologger.WarnLevel
+	default:
+		return ectologger.InfoLevel
+	}
+}
+
+// SkipPaths returns a Skip rule leaving out requests to any of paths, e.g. SkipPaths("/healthz", "/readyz").
+func SkipPaths(paths ...string) func(r *http.Request) bool {
+	skipped := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		skipped[p] = struct{}{}
+	}
+	return func(r *http.Request) bool {
+		_, ok := skipped[r.URL.Path]
+		return ok
+	}
+}
+
+// Middleware returns middleware that adds a request scoped logger, carrying the request ID,
+// method, path, remote address and user agent, to the context of each request, and writes an
+// access log line once the handler returns. Panics in the handler are recovered, logged at
+// error and answered with a 500 if nothing was written yet.
+// Handlers retrieve the logger with ectologger.FromContext.
+func Middleware(logger ectologger.Logger, opts Options) func(http.Handler) http.Handler {
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if opts.NewRequestID == nil {
+		opts.NewRequestID = newRequestID
+	}
+	if opts.Level == nil {
+		opts.Level = DefaultLevel
+	}
+	if opts.Skip == nil {
+		opts.Skip = func(*http.Request) bool { return false }
+	}
+	if opts.AccessLog == nil {
+		opts.AccessLog = StructuredAccessLog
+	}
+	if opts.Body != nil {
+		opts.Body = opts.Body.withDefaults()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestID := r.Header.Get(opts.RequestIDHeader)
+			if requestID == "" {
+				requestID = opts.NewRequestID()
+			}
+			w.Header().Set(opts.RequestIDHeader, requestID)
+
+			ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+			reqLogger := logger.WithTypedFields(
+				ectologger.String("request_id", requestID),
+				ectologger.String("http.method", r.Method),
+				ectologger.String("http.path", r.URL.Path),
+				ectologger.String("http.remote_addr", r.RemoteAddr),
+				ectologger.String("http.user_agent", r.UserAgent()),
+			).WithContext(ctx)
+			r = r.WithContext(ectologger.NewContext(ctx, reqLogger))
+			rw := &responseWriter{ResponseWriter: w, capture: opts.Body}
+
+			var reqBody *bodyBuffer
+			if opts.Body != nil && r.Body != nil && opts.Body.captures(r.Header.Get("Content-Type")) {
+				reqBody = newBodyBuffer(opts.Body.MaxBytes)
+				r.Body = &captureBody{ReadCloser: r.Body, buf: reqBody}
+			}
+
+			defer func() {
+				entry := AccessLog{Request: r, RequestID: requestID, Bytes: rw.bytes, Start: start, Panic: recover()}
+				if entry.Panic != nil && !rw.wroteHeader {
+					rw.WriteHeader(http.StatusInternalServerError)
+				}
+				entry.Status = rw.statusCode()
+				entry.Duration = time.Since(start)
+
+				level := opts.Level(entry.Status)
+				if entry.Panic != nil {
+					level = ectologger.ErrorLevel
+					reqLogger = reqLogger.WithTypedFields(ectologger.String("stack", string(debug.Stack())))
+				}
+				if entry.Panic != nil || !opts.Skip(r) {
+					if opts.Body != nil {
+						fields := opts.Body.fields("http.request", r.Header, reqBody)
+						fields = append(fields, opts.Body.fields("http.response", w.Header(), rw.body)...)
+						reqLogger = reqLogger.WithTypedFields(fields...)
+					}
+					opts.AccessLog(reqLogger, level, entry)
+				}
+
+				// Let the server abort the connection, as it would without the middleware
+				if entry.Panic == http.ErrAbortHandler {
+					panic(entry.Panic)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID the middleware added to ctx, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// StructuredAccessLog logs "request completed", or "request panicked", with the status,
+// bytes and duration of the response as fields.
+func StructuredAccessLog(logger ectologger.Logger, level string, entry AccessLog) {
+	msg := "request completed"
+	fields := []ectologger.Field{
+		ectologger.Int64("http.status", int64(entry.Status)),
+		ectologger.Int64("http.bytes", entry.Bytes),
+		ectologger.Duration("http.duration", entry.Duration),
+	}
+	if entry.Panic != nil {
+		msg = "request panicked"
+		fields = append(fields, ectologger.String("panic", fmt.Sprint(entry.Panic)))
+	}
+	ectologger.LogAt(logger.WithTypedFields(fields...), level, msg)
+}
+
+// newRequestID returns 16 random bytes in hex.
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter records the status code and body size written by a handler, and captures
+// the start of the body when body capture is enabled.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+
+	capture *BodyCapture
+	body    *bodyBuffer // Nil until the first write, and when the content type is not captured
+}
+
+// WriteHeader records the status code and passes it on.
+func (w *responseWriter) WriteHeader(status int) {
+	// Informational responses are followed by the final status
+	if !w.wroteHeader && status >= 200 {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written and passes them on.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.capture != nil && w.bytes == 0 && w.body == nil {
+		// Like net/http, sniff the content type when the handler did not set one
+		contentType := w.Header().Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(b)
+		}
+		if w.capture.captures(contentType) {
+			w.body = newBodyBuffer(w.capture.MaxBytes)
+		}
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	if w.body != nil {
+		_, _ = w.body.Write(b[:n])
+	}
+	return n, err
+}
+
+// Flush implements http.Flusher when the wrapped writer supports it.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker when the wrapped writer supports it, e.g. for websockets.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode returns the recorded status code, 200 if none was written.
+func (w *responseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,151 @@
+package httpmiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects the messages logged by the middleware and handlers.
+type recorder struct {
+	mu       sync.Mutex
+	messages []ectologger.EctoLogMessage
+}
+
+func (r *recorder) log(msg ectologger.EctoLogMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+}
+
+func (r *recorder) get() []ectologger.EctoLogMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ectologger.EctoLogMessage(nil), r.messages...)
+}
+
+func serve(handler http.Handler, opts Options, req *http.Request) (*httptest.ResponseRecorder, []ectologger.EctoLogMessage) {
+	rec := &recorder{}
+	w := httptest.NewRecorder()
+	Middleware(ectologger.NewEctoLogger(rec.log), opts)(handler).ServeHTTP(w, req)
+	return w, rec.get()
+}
+
+func TestMiddlewareAccessLog(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger, ok := ectologger.FromContext(r.Context())
+		require.True(t, ok)
+		logger.Info("handling")
+		assert.Equal(t, "abc", RequestIDFromContext(r.Context()))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users?id=1", nil)
+	req.Header.Set("X-Request-Id", "abc")
+	req.Header.Set("User-Agent", "test-agent")
+	w, logged := serve(handler, Options{}, req)
+
+	assert.Equal(t, "abc", w.Header().Get("X-Request-Id"))
+	require.Len(t, logged, 2)
+	assert.Equal(t, "handling", logged[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		"request_id":       "abc",
+		"http.method":      "POST",
+		"http.path":        "/users",
+		"http.remote_addr": "192.0.2.1:1234",
+		"http.user_agent":  "test-agent",
+	}, logged[0].FieldMap())
+
+	assert.Equal(t, ectologger.InfoLevel, logged[1].Level)
+	assert.Equal(t, "request completed", logged[1].Message)
+	fields := logged[1].FieldMap()
+	assert.Equal(t, int64(201), fields["http.status"])
+	assert.Equal(t, int64(5), fields["http.bytes"])
+	assert.Contains(t, fields, "http.duration")
+}
+
+func TestMiddlewareGeneratesRequestID(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	w, logged := serve(handler, Options{}, httptest.NewRequest(http.MethodGet, "/", nil))
+	id := w.Header().Get("X-Request-Id")
+	assert.Len(t, id, 32)
+	require.Len(t, logged, 1)
+	assert.Equal(t, id, logged[0].FieldMap()["request_id"])
+	assert.Equal(t, int64(200), logged[0].FieldMap()["http.status"])
+
+	w, _ = serve(handler, Options{RequestIDHeader: "X-Trace", NewRequestID: func() string { return "fixed" }},
+		httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "fixed", w.Header().Get("X-Trace"))
+}
+
+func TestMiddlewareLevels(t *testing.T) {
+	for status, level := range map[int]string{
+		http.StatusOK:                  ectologger.InfoLevel,
+		http.StatusFound:               ectologger.InfoLevel,
+		http.StatusNotFound:            ectologger.WarnLevel,
+		http.StatusServiceUnavailable:  ectologger.ErrorLevel,
+		http.StatusInternalServerError: ectologger.ErrorLevel,
+	} {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(status) })
+		_, logged := serve(handler, Options{}, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Len(t, logged, 1)
+		assert.Equal(t, level, logged[0].Level, "status %d", status)
+	}
+}
+
+func TestMiddlewareSkip(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := ectologger.FromContext(r.Context())
+		assert.True(t, ok)
+	})
+	opts := Options{Skip: SkipPaths("/healthz")}
+
+	_, logged := serve(handler, opts, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Empty(t, logged)
+	_, logged = serve(handler, opts, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Len(t, logged, 1)
+}
+
+func TestMiddlewareRecoversPanics(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	w, logged := serve(handler, Options{Skip: SkipPaths("/")}, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Len(t, logged, 1, "panics are logged even when skipped")
+	assert.Equal(t, ectologger.ErrorLevel, logged[0].Level)
+	assert.Equal(t, "request panicked", logged[0].Message)
+	fields := logged[0].FieldMap()
+	assert.Equal(t, "boom", fields["panic"])
+	assert.Equal(t, int64(500), fields["http.status"])
+	assert.Contains(t, fields["stack"], "TestMiddlewareRecoversPanics")
+}
+
+func TestMiddlewareAbortHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(handler, Options{}, httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		flusher.Flush()
+	})
+
+	w, _ := serve(handler, Options{}, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, w.Flushed)
+}
@@ -0,0 +1,112 @@
+package httpmiddleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+)
+
+// TransportOptions configures a Transport.
+type TransportOptions struct {
+	// Level returns the level a response with status is logged at. Defaults to DefaultLevel.
+	Level func(status int) string
+	// Skip reports whether the request is not logged.
+	Skip func(r *http.Request) bool
+	// Body enables logging of request and response bodies and headers. Disabled when nil.
+	Body *BodyCapture
+}
+
+// Transport is an http.RoundTripper that logs outgoing requests with their method, URL,
+// status, duration and, when enabled, bodies and headers. Requests are logged through the
+// logger in their context, as added by Middleware, falling back to the Transport's logger.
+//
+// A response is logged once its body has been read to the end or closed, so that the
+// captured body is complete and the duration covers the whole exchange.
+type Transport struct {
+	next   http.RoundTripper
+	logger ectologger.Logger
+	opts   TransportOptions
+}
+
+// NewTransport returns a Transport that sends requests through next, http.DefaultTransport when nil.
+func NewTransport(logger ectologger.Logger, next http.RoundTripper, opts TransportOptions) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if opts.Level == nil {
+		opts.Level = DefaultLevel
+	}
+	if opts.Skip == nil {
+		opts.Skip = func(*http.Request) bool { return false }
+	}
+	if opts.Body != nil {
+		opts.Body = opts.Body.withDefaults()
+	}
+	return &Transport{next: next, logger: logger, opts: opts}
+}
+
+// RoundTrip sends req and logs the exchange.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.opts.Skip(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	start := time.Now()
+	logger, ok := ectologger.FromContext(req.Context())
+	if !ok {
+		logger = t.logger
+	}
+	logger = logger.WithTypedFields(
+		ectologger.String("http.method", req.Method),
+		ectologger.String("http.url", redactURL(req)),
+	).WithContext(req.Context())
+
+	var reqBody *bodyBuffer
+	if t.opts.Body != nil && req.Body != nil && req.Body != http.NoBody && t.opts.Body.captures(req.Header.Get("Content-Type")) {
+		// RoundTrip must not modify req, so the body is swapped on a shallow copy
+		reqBody = newBodyBuffer(t.opts.Body.MaxBytes)
+		clone := *req
+		clone.Body = &captureBody{ReadCloser: req.Body, buf: reqBody}
+		req = &clone
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		if t.opts.Body != nil {
+			logger = logger.WithTypedFields(t.opts.Body.fields("http.request", req.Header, reqBody)...)
+		}
+		logger.WithTypedFields(ectologger.Duration("http.duration", time.Since(start))).
+			WithError(err).
+			Error("outbound request failed")
+		return nil, err
+	}
+
+	var respBody *bodyBuffer
+	if t.opts.Body != nil && t.opts.Body.captures(resp.Header.Get("Content-Type")) {
+		respBody = newBodyBuffer(t.opts.Body.MaxBytes)
+	}
+	body := &captureBody{ReadCloser: resp.Body, buf: respBody}
+	body.onClose = func() {
+		fields := []ectologger.Field{
+			ectologger.Int64("http.status", int64(resp.StatusCode)),
+			ectologger.Int64("http.bytes", body.read.Load()),
+			ectologger.Duration("http.duration", time.Since(start)),
+		}
+		if t.opts.Body != nil {
+			fields = append(fields, t.opts.Body.fields("http.request", req.Header, reqBody)...)
+			fields = append(fields, t.opts.Body.fields("http.response", resp.Header, respBody)...)
+		}
+		ectologger.LogAt(logger.WithTypedFields(fields...), t.opts.Level(resp.StatusCode), "outbound request completed")
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// redactURL returns the URL of req without the password of its user info.
+func redactURL(req *http.Request) string {
+	if req.URL == nil {
+		return ""
+	}
+	return req.URL.Redacted()
+}
@@ -17,6 +17,24 @@ type Logger interface {
 	// WithField returns a new Logger with the given key-value pair added to the logging context.
 	WithField(key string, value interface{}) Logger
 
+	// WithTypedFields returns a new Logger with the given typed fields added to the logging context.
+	// Typed fields keep their order and type, letting encoders write them without reflection.
+	WithTypedFields(fields ...Field) Logger
+
+	// WithGroup returns a new Logger that nests all subsequently added fields under name,
+	// so fields from different components cannot collide.
+	WithGroup(name string) Logger
+
+	// Named returns a new Logger with name appended to the current name, separated by a dot,
+	// so Named("db").Named("pool") is named "db.pool". The name is logged in the "logger" field
+	// and selects the minimum level set for it with WithLevelRegistry.
+	Named(name string) Logger
+
+	// V returns the Logger itself if verbosity level is enabled at the call site, and a Logger
+	// discarding everything otherwise, as in logger.V(2).Info("cache miss"). The levels are set
+	// with WithVerbosity; without it only V(0) is enabled.
+	V(level int) Logger
+
 	// WithContext returns a new Logger with the given context added to the logging context.
 	WithContext(ctx context.Context) Logger
 
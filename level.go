@@ -0,0 +1,77 @@
+package ectologger
+
+// Log levels used in EctoLogMessage.Level.
+const (
+	TraceLevel = "trace"
+	DebugLevel = "debug"
+	InfoLevel  = "info"
+	WarnLevel  = "warn"
+	ErrorLevel = "error"
+	FatalLevel = "fatal"
+)
+
+// LevelRank returns the severity of level, higher is more severe.
+// Unknown levels rank as info, matching how the zap adapter treats them.
+func LevelRank(level string) int {
+	switch level {
+	case TraceLevel:
+		return 0
+	case DebugLevel:
+		return 1
+	case InfoLevel:
+		return 2
+	case WarnLevel:
+		return 3
+	case ErrorLevel:
+		return 4
+	case FatalLevel:
+		return 5
+	default:
+		return 2
+	}
+}
+
+// LevelEnabled reports whether level is at least as severe as min.
+func LevelEnabled(level string, min string) bool {
+	return LevelRank(level) >= LevelRank(min)
+}
+
+// LogAt logs msg through logger at level, for callers that choose the level at run time.
+// Loggers built by NewEctoLogger log trace messages at the trace level; other Logger
+// implementations have no trace method and get them at debug. Unknown levels are logged at info.
+func LogAt(logger Logger, level, msg string) {
+	if l, ok := logger.(levelLogger); ok {
+		if !validLevel(level) {
+			level = InfoLevel
+		}
+		l.logAt(level, msg)
+		return
+	}
+	switch level {
+	case TraceLevel, DebugLevel:
+		logger.Debug(msg)
+	case WarnLevel:
+		logger.Warn(msg)
+	case ErrorLevel:
+		logger.Error(msg)
+	case FatalLevel:
+		logger.Fatal(msg)
+	default:
+		logger.Info(msg)
+	}
+}
+
+// levelLogger is implemented by the loggers of this package, which can log at any level.
+type levelLogger interface {
+	logAt(level, msg string)
+}
+
+// validLevel reports whether level is one of the level constants.
+func validLevel(level string) bool {
+	switch level {
+	case TraceLevel, DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel:
+		return true
+	default:
+		return false
+	}
+}
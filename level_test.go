@@ -0,0 +1,36 @@
+package ectologger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogAt(t *testing.T) {
+	rec := &messageRecorder{}
+	logger := NewEctoLogger(rec.Log)
+
+	LogAt(logger, TraceLevel, "trace")
+	LogAt(logger.WithField("key", "value"), WarnLevel, "warn")
+	LogAt(logger, "verbose", "unknown")
+
+	levels := make([]string, len(rec.messages))
+	for i, msg := range rec.messages {
+		levels[i] = msg.Level
+	}
+	assert.Equal(t, []string{TraceLevel, WarnLevel, InfoLevel}, levels)
+	assert.Equal(t, "value", rec.messages[1].FieldMap()["key"])
+}
+
+func TestLogAtGatesNamedLoggers(t *testing.T) {
+	registry, err := NewLevelRegistry("db=warn")
+	require.NoError(t, err)
+	rec := &messageRecorder{}
+	db := NewEctoLogger(rec.Log, WithLevelRegistry(registry)).Named("db")
+
+	LogAt(db, InfoLevel, "dropped")
+	LogAt(db, ErrorLevel, "logged")
+
+	assert.Equal(t, []string{"logged"}, rec.Messages())
+}
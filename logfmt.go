@@ -0,0 +1,140 @@
+package ectologger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LogfmtEctoLogFunc is a log function that writes the log message as logfmt key=value pairs.
+// Fields nested in groups, objects or maps are written with dotted keys, e.g. request.id=123.
+func LogfmtEctoLogFunc(msg EctoLogMessage) {
+	stdLogger().Print(encodeLogfmt(msg))
+}
+
+// NewLogfmtLogFunc returns a log function that writes messages to w as logfmt lines, in the format
+// of LogfmtEctoLogFunc but without the prefix of the standard library logger. Writes are serialized.
+func NewLogfmtLogFunc(w io.Writer) EctoLogFunc {
+	lw := &lineWriter{w: w}
+	return func(msg EctoLogMessage) {
+		lw.writeLine([]byte(encodeLogfmt(msg)))
+	}
+}
+
+// encodeLogfmt resolves any LogValuers and formats msg as a logfmt line without a trailing newline.
+func encodeLogfmt(msg EctoLogMessage) string {
+	msg = msg.Resolve()
+
+	var b strings.Builder
+	writeLogfmtPair(&b, "time", messageTime(msg).Format(time.RFC3339))
+	writeLogfmtPair(&b, "level", msg.Level)
+	writeLogfmtPair(&b, "message", msg.Message)
+	if msg.Err != nil {
+		writeLogfmtPair(&b, "err", msg.Err.Error())
+	}
+
+	keys := make([]string, 0, len(msg.Fields))
+	for k := range msg.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeLogfmtValue(&b, k, msg.Fields[k])
+	}
+
+	writeLogfmtFields(&b, "", msg.TypedFields)
+	return b.String()
+}
+
+// NewLogfmtEctoLogger returns a new EctoLogger that logs logfmt lines to the default logger
+func NewLogfmtEctoLogger() Logger {
+	return NewEctoLogger(LogfmtEctoLogFunc)
+}
+
+// writeLogfmtFields writes typed fields with keys prefixed by prefix.
+// A namespace extends the prefix for every field that follows it.
+func writeLogfmtFields(b *strings.Builder, prefix string, fields []Field) {
+	for _, f := range fields {
+		switch f.Type {
+		case NamespaceType:
+			prefix += f.Key + "."
+		case ObjectType:
+			nested, _ := f.Interface.([]Field)
+			writeLogfmtFields(b, prefix+f.Key+".", nested)
+		case SkipType:
+		default:
+			writeLogfmtValue(b, prefix+f.Key, f.Value())
+		}
+	}
+}
+
+// writeLogfmtValue writes a single value, flattening nested maps into dotted keys.
+func writeLogfmtValue(b *strings.Builder, key string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeLogfmtValue(b, key+"."+k, v[k])
+		}
+	case string:
+		writeLogfmtPair(b, key, v)
+	case int64:
+		writeLogfmtPair(b, key, strconv.FormatInt(v, 10))
+	case float64:
+		writeLogfmtPair(b, key, strconv.FormatFloat(v, 'g', -1, 64))
+	case bool:
+		writeLogfmtPair(b, key, strconv.FormatBool(v))
+	case time.Duration:
+		writeLogfmtPair(b, key, v.String())
+	case time.Time:
+		writeLogfmtPair(b, key, v.Format(time.RFC3339))
+	case error:
+		writeLogfmtPair(b, key, v.Error())
+	case fmt.Stringer:
+		writeLogfmtPair(b, key, v.String())
+	case nil:
+		writeLogfmtPair(b, key, "null")
+	default:
+		if data, err := json.Marshal(v); err == nil {
+			writeLogfmtPair(b, key, string(data))
+		} else {
+			writeLogfmtPair(b, key, fmt.Sprint(v))
+		}
+	}
+}
+
+// writeLogfmtPair writes key=value, quoting the value when it would otherwise be ambiguous.
+func writeLogfmtPair(b *strings.Builder, key string, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+	if logfmtNeedsQuoting(value) {
+		b.WriteString(strconv.Quote(value))
+	} else {
+		b.WriteString(value)
+	}
+}
+
+// logfmtNeedsQuoting reports whether value is empty or contains spaces, quotes, equals signs or control characters.
+func logfmtNeedsQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package ectologger
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtEctoLogFunc(t *testing.T) {
+	var logOutput string
+	log.SetOutput(writerFunc(func(p []byte) (int, error) {
+		logOutput = string(p)
+		return len(p), nil
+	}))
+	log.SetFlags(0)
+	defer log.SetFlags(log.LstdFlags)
+
+	logger := NewEctoLogger(LogfmtEctoLogFunc)
+	logger.WithField("user", "jane doe").
+		WithError(errors.New("test error")).
+		WithGroup("db").
+		WithTypedFields(Int64("rows", 3), Object("pool", Bool("idle", true))).
+		Warn("query failed")
+
+	line := strings.TrimSuffix(logOutput, "\n")
+	assert.Regexp(t, `^time=\S+ `, line)
+	assert.Contains(t, line, ` level=warn message="query failed" err="test error" user="jane doe" db.rows=3 db.pool.idle=true`)
+}
+
+func TestLogfmtNeedsQuoting(t *testing.T) {
+	assert.True(t, logfmtNeedsQuoting(""))
+	assert.True(t, logfmtNeedsQuoting("a b"))
+	assert.True(t, logfmtNeedsQuoting("a=b"))
+	assert.True(t, logfmtNeedsQuoting(`a"b`))
+	assert.False(t, logfmtNeedsQuoting("plain"))
+}
+
+func TestNewLogfmtLogFunc(t *testing.T) {
+	var buf strings.Builder
+	logger := NewEctoLogger(NewLogfmtLogFunc(&buf), WithClock(newManualClock()))
+
+	logger.WithField("user", "jane").Info("first")
+	logger.Warn("second")
+
+	assert.Equal(t, "time=2024-01-01T00:00:00Z level=info message=first user=jane\n"+
+		"time=2024-01-01T00:00:00Z level=warn message=second\n", buf.String())
+}
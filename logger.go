@@ -4,7 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"sync"
 	"time"
 
 	"github.com/Gobusters/ectolinq"
@@ -12,11 +13,23 @@ import (
 
 // EctoLogMessage represents a log message with its associated metadata.
 type EctoLogMessage struct {
-	Level   string                 // The log level of the message. One of: debug, info, warn, error, fatal
+	Level   string                 // The log level of the message. One of: trace, debug, info, warn, error, fatal
 	Message string                 // The log message
 	Fields  map[string]interface{} // Fields to add to the log message
 	Ctx     context.Context        // The context of the log message
 	Err     error                  // The error to add to the log message
+	Time    time.Time              // When the message was logged, read from the logger's Clock at the call site
+
+	TypedFields []Field // Typed fields to add to the log message, in the order they were added
+}
+
+// FieldMap returns the map fields and typed fields of the message merged into a single map.
+// Typed fields take precedence over map fields with the same key.
+func (msg EctoLogMessage) FieldMap() map[string]interface{} {
+	if len(msg.TypedFields) == 0 {
+		return msg.Fields
+	}
+	return ectolinq.Merge(msg.Fields, FieldsToMap(msg.TypedFields))
 }
 
 // EctoLogFunc is a function type that defines how a log message should be processed.
@@ -24,32 +37,102 @@ type EctoLogFunc func(msg EctoLogMessage)
 
 // EctoLogger is the main logger struct that implements the Logger interface.
 type EctoLogger struct {
-	logFunc EctoLogFunc
+	logFunc    EctoLogFunc // next behind the level filter of levels for unnamed loggers
+	next       EctoLogFunc // The log function with its processors, named loggers filter it by their own level
+	collisions CollisionPolicy
+	processors []Processor
+	clock      Clock
+	levels     *LevelRegistry
+	verbosity  *Verbosity
+}
+
+// Option configures an EctoLogger created by NewEctoLogger.
+type Option func(l *EctoLogger)
+
+// WithCollisionPolicy sets how the logger and its sub loggers handle fields added with a key
+// that already exists in the same group. The default is CollisionOverwrite.
+func WithCollisionPolicy(policy CollisionPolicy) Option {
+	return func(l *EctoLogger) {
+		l.collisions = policy
+	}
 }
 
-// NewEctoLogger creates a new EctoLogger with the given log function.
-func NewEctoLogger(logFunc EctoLogFunc) Logger {
-	return &EctoLogger{logFunc: logFunc}
+// NewEctoLogger creates a new EctoLogger with the given log function and options.
+func NewEctoLogger(logFunc EctoLogFunc, opts ...Option) Logger {
+	l := &EctoLogger{logFunc: logFunc}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.clock == nil {
+		l.clock = SystemClock
+	}
+	if len(l.processors) > 0 {
+		l.logFunc = Chain(l.logFunc, l.processors...)
+	}
+	l.next = l.logFunc
+	l.logFunc = gateLevels(l.next, l.levels, "")
+	return l
 }
 
 // DefaultEctoLogFunc is the default log function.
-// It marshals the log message to JSON and writes it to stdout.
+// It resolves any LogValuers, marshals the log message to JSON and writes it to stdout.
 func DefaultEctoLogFunc(msg EctoLogMessage) {
-	jsonMsg := make(map[string]interface{}, len(msg.Fields)+4) // Pre-allocate map with estimated size
+	json, err := encodeJSON(msg)
+	if err != nil {
+		stdLogger().Printf("Error marshalling log message to JSON: %v", err)
+		return
+	}
+
+	stdLogger().Print(string(json)) // Avoid unnecessary string formatting
+}
+
+// NewJSONLogFunc returns a log function that writes messages to w as JSON lines, in the format of
+// DefaultEctoLogFunc but without the prefix of the standard library logger. Writes are serialized.
+func NewJSONLogFunc(w io.Writer) EctoLogFunc {
+	lw := &lineWriter{w: w}
+	return func(msg EctoLogMessage) {
+		json, err := encodeJSON(msg)
+		if err != nil {
+			reportError(fmt.Errorf("ectologger: marshalling log message to JSON: %w", err))
+			return
+		}
+		lw.writeLine(json)
+	}
+}
+
+// encodeJSON resolves any LogValuers and marshals msg to a JSON object.
+func encodeJSON(msg EctoLogMessage) ([]byte, error) {
+	msg = msg.Resolve()
+
+	jsonMsg := make(map[string]interface{}, len(msg.Fields)+len(msg.TypedFields)+4) // Pre-allocate map with estimated size
 	jsonMsg["level"] = msg.Level
 	jsonMsg["message"] = msg.Message
-	jsonMsg["err"] = msg.Err.Error()
-	jsonMsg["time"] = time.Now().Format(time.RFC3339)
+	if msg.Err != nil {
+		jsonMsg["err"] = msg.Err.Error()
+	}
+	jsonMsg["time"] = messageTime(msg).Format(time.RFC3339)
 
-	jsonMsg = ectolinq.Merge(jsonMsg, msg.Fields)
+	jsonMsg = ectolinq.Merge(jsonMsg, msg.FieldMap())
 
-	json, err := json.Marshal(jsonMsg)
-	if err != nil {
-		log.Printf("Error marshalling log message to JSON: %v", err)
-		return
-	}
+	return json.Marshal(jsonMsg)
+}
+
+// lineWriter serializes the lines written by a log function to a writer.
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// writeLine writes line followed by a newline in a single Write call.
+func (lw *lineWriter) writeLine(line []byte) {
+	buf := make([]byte, 0, len(line)+1)
+	buf = append(append(buf, line...), '\n')
 
-	log.Print(string(json)) // Avoid unnecessary string formatting
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if _, err := lw.w.Write(buf); err != nil {
+		reportError(fmt.Errorf("ectologger: writing log message: %w", err))
+	}
 }
 
 // NewDefaultEctoLogger returns a new EctoLogger that logs to the default logger
@@ -57,194 +140,292 @@ func NewDefaultEctoLogger() Logger {
 	return NewEctoLogger(DefaultEctoLogFunc)
 }
 
+// newSubLogger returns an empty sub logger that shares the configuration of l.
+func (l *EctoLogger) newSubLogger() *ectoSubLogger {
+	return &ectoSubLogger{logFunc: l.logFunc, next: l.next, levels: l.levels, verbosity: l.verbosity, fields: map[string]interface{}{}, collisions: l.collisions, clock: l.clock}
+}
+
 // WithFields returns a new Logger with the given fields added to the logging context.
 func (l *EctoLogger) WithFields(fields map[string]interface{}) Logger {
-	return &ectoSubLogger{logFunc: l.logFunc, fields: fields}
+	return l.newSubLogger().WithFields(fields)
 }
 
 // WithField returns a new Logger with the given key-value pair added to the logging context.
 func (l *EctoLogger) WithField(key string, value interface{}) Logger {
-	return &ectoSubLogger{logFunc: l.logFunc, fields: map[string]interface{}{key: value}}
+	return l.newSubLogger().WithField(key, value)
+}
+
+// WithTypedFields returns a new Logger with the given typed fields added to the logging context.
+func (l *EctoLogger) WithTypedFields(fields ...Field) Logger {
+	return l.newSubLogger().WithTypedFields(fields...)
+}
+
+// WithGroup returns a new Logger that nests all subsequently added fields under name.
+func (l *EctoLogger) WithGroup(name string) Logger {
+	return l.newSubLogger().WithGroup(name)
+}
+
+// Named returns a new Logger named name, see Logger.Named.
+func (l *EctoLogger) Named(name string) Logger {
+	return l.newSubLogger().Named(name)
+}
+
+// V returns l if verbosity level is enabled at the call site, see Logger.V.
+func (l *EctoLogger) V(level int) Logger {
+	if l.verbosity.enabled(level) {
+		return l
+	}
+	return nopLogger{}
 }
 
 // WithContext returns a new Logger with the given context added to the logging context.
 func (l *EctoLogger) WithContext(ctx context.Context) Logger {
-	return &ectoSubLogger{logFunc: l.logFunc, fields: map[string]interface{}{}, ctx: ctx}
+	return l.newSubLogger().WithContext(ctx)
 }
 
 // WithError returns a new Logger with the given error added to the logging context.
 func (l *EctoLogger) WithError(err error) Logger {
-	return &ectoSubLogger{logFunc: l.logFunc, fields: map[string]interface{}{}, err: err}
+	return l.newSubLogger().WithError(err)
 }
 
 // Debug logs a message at the Debug level.
 func (l *EctoLogger) Debug(msg string) {
-	l.logFunc(EctoLogMessage{Level: "debug", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: DebugLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) Debugf(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "debug", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: DebugLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) DebugContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "debug", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: DebugLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *EctoLogger) DebugContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "debug", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: DebugLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 
 // Info logs a message at the Info level.
 func (l *EctoLogger) Info(msg string) {
-	l.logFunc(EctoLogMessage{Level: "info", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: InfoLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) Infof(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "info", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: InfoLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) InfoContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "info", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: InfoLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *EctoLogger) InfoContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "info", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: InfoLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 
 // Warn logs a message at the Warn level.
 func (l *EctoLogger) Warn(msg string) {
-	l.logFunc(EctoLogMessage{Level: "warn", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: WarnLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) Warnf(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "warn", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: WarnLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) WarnContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "warn", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: WarnLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *EctoLogger) WarnContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "warn", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: WarnLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 
 // Error logs a message at the Error level.
 func (l *EctoLogger) Error(msg string) {
-	l.logFunc(EctoLogMessage{Level: "error", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: ErrorLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) Errorf(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "error", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: ErrorLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) ErrorContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "error", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: ErrorLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *EctoLogger) ErrorContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "error", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: ErrorLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 
 // Fatal logs a message at the Fatal level.
 func (l *EctoLogger) Fatal(msg string) {
-	l.logFunc(EctoLogMessage{Level: "fatal", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: FatalLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) Fatalf(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "fatal", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil})
+	l.logFunc(EctoLogMessage{Level: FatalLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 func (l *EctoLogger) FatalContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "fatal", Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: FatalLevel, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *EctoLogger) FatalContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "fatal", Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: FatalLevel, Message: fmt.Sprintf(format, args...), Fields: map[string]interface{}{}, Err: nil, Ctx: ctx, Time: l.clock.Now()})
+}
+
+// logAt logs a message at level, see LogAt.
+func (l *EctoLogger) logAt(level, msg string) {
+	l.logFunc(EctoLogMessage{Level: level, Message: msg, Fields: map[string]interface{}{}, Err: nil, Ctx: nil, Time: l.clock.Now()})
 }
 
 // ectoSubLogger is an internal type that represents a logger with additional context.
 type ectoSubLogger struct {
-	logFunc EctoLogFunc
-	fields  map[string]interface{}
-	err     error
-	ctx     context.Context
+	logFunc     EctoLogFunc
+	next        EctoLogFunc // logFunc without the level filter of the logger name
+	levels      *LevelRegistry
+	verbosity   *Verbosity
+	name        string
+	fields      map[string]interface{} // Map fields added while no group was open
+	typedFields []Field                // Typed fields, and map fields added inside a group, in order
+	groups      []string               // Names of the open groups, outermost first
+	collisions  CollisionPolicy
+	clock       Clock
+	err         error
+	ctx         context.Context
+}
+
+// clone returns a copy of l that can be modified without affecting l or loggers derived from it.
+func (l *ectoSubLogger) clone() *ectoSubLogger {
+	c := *l
+	// Cap the slices so appending to the copy never writes into the backing array of l
+	c.typedFields = l.typedFields[:len(l.typedFields):len(l.typedFields)]
+	c.groups = l.groups[:len(l.groups):len(l.groups)]
+	return &c
 }
 
 // WithFields returns a new Logger with the given fields added to the logging context.
 func (l *ectoSubLogger) WithFields(fields map[string]interface{}) Logger {
-	l.fields = ectolinq.Merge(l.fields, fields)
-	return l
+	c := l.clone()
+	c.addMap(fields)
+	return c
 }
 
 // WithField returns a new Logger with the given key-value pair added to the logging context.
 func (l *ectoSubLogger) WithField(key string, value interface{}) Logger {
-	l.fields = ectolinq.Merge(l.fields, map[string]interface{}{key: value})
-	return l
+	c := l.clone()
+	c.fields = copyFields(c.fields, 1)
+	c.addField(Any(key, value), value, true)
+	return c
+}
+
+// WithTypedFields returns a new Logger with the given typed fields added to the logging context.
+func (l *ectoSubLogger) WithTypedFields(fields ...Field) Logger {
+	c := l.clone()
+	for _, f := range fields {
+		c.addField(f, nil, false)
+	}
+	return c
+}
+
+// WithGroup returns a new Logger that nests all subsequently added fields under name.
+func (l *ectoSubLogger) WithGroup(name string) Logger {
+	c := l.clone()
+	c.addField(Namespace(name), nil, false)
+	return c
+}
+
+// Named returns a new Logger with name appended to the current name, separated by a dot.
+func (l *ectoSubLogger) Named(name string) Logger {
+	c := l.clone()
+	if c.name != "" {
+		name = c.name + "." + name
+	}
+	c.name = name
+	// The name is kept out of groups and replaces the previous one regardless of the collision policy
+	c.fields = copyFields(c.fields, 1)
+	c.fields[LoggerNameKey] = name
+	c.logFunc = gateLevels(c.next, c.levels, name)
+	return c
+}
+
+// V returns l if verbosity level is enabled at the call site, see Logger.V.
+func (l *ectoSubLogger) V(level int) Logger {
+	if l.verbosity.enabled(level) {
+		return l
+	}
+	return nopLogger{}
 }
 
 // WithContext returns a new Logger with the given context added to the logging context.
 func (l *ectoSubLogger) WithContext(ctx context.Context) Logger {
-	l.ctx = ctx
-	return l
+	c := l.clone()
+	c.ctx = ctx
+	return c
 }
 
 // WithError returns a new Logger with the given error added to the logging context.
 func (l *ectoSubLogger) WithError(err error) Logger {
-	l.err = err
-	return l
+	c := l.clone()
+	c.err = err
+	return c
 }
 
 // Debug logs a message at the Debug level.
 func (l *ectoSubLogger) Debug(msg string) {
-	l.logFunc(EctoLogMessage{Level: "debug", Message: msg, Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: DebugLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) Debugf(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "debug", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: DebugLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) DebugContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "debug", Message: msg, Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: DebugLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) DebugContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "debug", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: DebugLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 
 // Info logs a message at the Info level.
 func (l *ectoSubLogger) Info(msg string) {
-	l.logFunc(EctoLogMessage{Level: "info", Message: msg, Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: InfoLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) Infof(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "info", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: InfoLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) InfoContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "info", Message: msg, Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: InfoLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) InfoContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "info", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: InfoLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 
 // Warn logs a message at the Warn level.
 func (l *ectoSubLogger) Warn(msg string) {
-	l.logFunc(EctoLogMessage{Level: "warn", Message: msg, Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: WarnLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) Warnf(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "warn", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: WarnLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) WarnContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "warn", Message: msg, Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: WarnLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) WarnContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "warn", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: WarnLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 
 // Error logs a message at the Error level.
 func (l *ectoSubLogger) Error(msg string) {
-	l.logFunc(EctoLogMessage{Level: "error", Message: msg, Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: ErrorLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) Errorf(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "error", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: ErrorLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) ErrorContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "error", Message: msg, Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: ErrorLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) ErrorContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "error", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: ErrorLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 
 // Fatal logs a message at the Fatal level.
 func (l *ectoSubLogger) Fatal(msg string) {
-	l.logFunc(EctoLogMessage{Level: "fatal", Message: msg, Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: FatalLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) Fatalf(format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "fatal", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: l.ctx})
+	l.logFunc(EctoLogMessage{Level: FatalLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) FatalContext(ctx context.Context, msg string) {
-	l.logFunc(EctoLogMessage{Level: "fatal", Message: msg, Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: FatalLevel, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
 }
 func (l *ectoSubLogger) FatalContextf(ctx context.Context, format string, args ...any) {
-	l.logFunc(EctoLogMessage{Level: "fatal", Message: fmt.Sprintf(format, args...), Fields: l.fields, Err: l.err, Ctx: ctx})
+	l.logFunc(EctoLogMessage{Level: FatalLevel, Message: fmt.Sprintf(format, args...), Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: ctx, Time: l.clock.Now()})
+}
+
+// logAt logs a message at level, see LogAt.
+func (l *ectoSubLogger) logAt(level, msg string) {
+	l.logFunc(EctoLogMessage{Level: level, Message: msg, Fields: l.fields, TypedFields: l.typedFields, Err: l.err, Ctx: l.ctx, Time: l.clock.Now()})
 }
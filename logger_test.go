@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,6 +49,36 @@ func TestEctoLoggerWithField(t *testing.T) {
 	assert.IsType(t, &ectoSubLogger{}, subLogger)
 }
 
+func TestEctoLoggerWithTypedFields(t *testing.T) {
+	var capturedMsg EctoLogMessage
+	logger := NewEctoLogger(func(msg EctoLogMessage) {
+		capturedMsg = msg
+	})
+
+	logger.WithTypedFields(String("key", "value")).
+		WithField("map_key", "map_value").
+		WithTypedFields(Int64("count", 1)).
+		Info("test message")
+
+	assert.Equal(t, []Field{String("key", "value"), Int64("count", 1)}, capturedMsg.TypedFields)
+	assert.Equal(t, map[string]interface{}{"map_key": "map_value"}, capturedMsg.Fields)
+}
+
+func TestEctoSubLoggerWithReturnsIndependentLoggers(t *testing.T) {
+	var captured []EctoLogMessage
+	base := NewEctoLogger(func(msg EctoLogMessage) {
+		captured = append(captured, msg)
+	}).WithField("base", "value").WithTypedFields(String("typed", "value"))
+
+	base.WithField("a", 1).WithTypedFields(Int64("n", 1)).Info("a")
+	base.WithField("b", 2).WithTypedFields(Int64("n", 2)).Info("b")
+	base.Info("base")
+
+	assert.Equal(t, map[string]interface{}{"base": "value", "typed": "value", "a": 1, "n": int64(1)}, captured[0].FieldMap())
+	assert.Equal(t, map[string]interface{}{"base": "value", "typed": "value", "b": 2, "n": int64(2)}, captured[1].FieldMap())
+	assert.Equal(t, map[string]interface{}{"base": "value", "typed": "value"}, captured[2].FieldMap())
+}
+
 func TestEctoLoggerWithContext(t *testing.T) {
 	originalLogger := NewDefaultEctoLogger()
 	ctx := context.Background()
@@ -147,6 +179,7 @@ func TestDefaultEctoLogFunc(t *testing.T) {
 		Fields:  map[string]interface{}{"key": "value"},
 		Ctx:     context.Background(),
 		Err:     errors.New("test error"),
+		Time:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
 	}
 
 	// Capture the output of log.Print
@@ -171,7 +204,57 @@ func TestDefaultEctoLogFunc(t *testing.T) {
 	assert.Equal(t, "test message", parsedOutput["message"])
 	assert.Equal(t, "test error", parsedOutput["err"])
 	assert.Equal(t, "value", parsedOutput["key"])
-	assert.NotEmpty(t, parsedOutput["time"])
+	assert.Equal(t, "2024-01-01T12:00:00Z", parsedOutput["time"])
+}
+
+func TestDefaultEctoLogFuncTypedFields(t *testing.T) {
+	msg := EctoLogMessage{
+		Level:       "info",
+		Message:     "test message",
+		Fields:      map[string]interface{}{},
+		TypedFields: []Field{String("key", "value"), Namespace("nested"), Int64("count", 1)},
+	}
+
+	var logOutput string
+	log.SetOutput(writerFunc(func(p []byte) (int, error) {
+		logOutput = string(p)
+		return len(p), nil
+	}))
+
+	DefaultEctoLogFunc(msg)
+
+	logJSON := logOutput[20 : len(logOutput)-1]
+
+	var parsedOutput map[string]interface{}
+	err := json.Unmarshal([]byte(logJSON), &parsedOutput)
+	require.NoError(t, err)
+
+	assert.Equal(t, "value", parsedOutput["key"])
+	assert.Equal(t, map[string]interface{}{"count": float64(1)}, parsedOutput["nested"])
+	assert.NotContains(t, parsedOutput, "err")
+}
+
+func TestNewJSONLogFunc(t *testing.T) {
+	var buf strings.Builder
+	logger := NewEctoLogger(NewJSONLogFunc(&buf), WithClock(newManualClock()))
+
+	logger.WithField("user", "jane").Info("first")
+	logger.WithError(errors.New("test error")).Error("second")
+
+	assert.Equal(t, `{"level":"info","message":"first","time":"2024-01-01T00:00:00Z","user":"jane"}`+"\n"+
+		`{"err":"test error","level":"error","message":"second","time":"2024-01-01T00:00:00Z"}`+"\n", buf.String())
+}
+
+func TestNewJSONLogFuncReportsWriteErrors(t *testing.T) {
+	var reported error
+	SetErrorHandler(func(err error) { reported = err })
+	defer SetErrorHandler(DefaultErrorHandler)
+
+	NewEctoLogger(NewJSONLogFunc(writerFunc(func(p []byte) (int, error) {
+		return 0, errors.New("disk full")
+	}))).Info("test message")
+
+	assert.EqualError(t, reported, "ectologger: writing log message: disk full")
 }
 
 // writerFunc is a helper type to capture log output
@@ -0,0 +1,214 @@
+package logradapter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/go-logr/logr"
+)
+
+// noValue is logged for the last key of an odd number of keys and values, following funcr.
+const noValue = "<no-value>"
+
+// NameKey is the key of the field holding the dotted name built by WithName.
+const NameKey = ectologger.LoggerNameKey
+
+// Options configures a LogSink.
+type Options struct {
+	// Verbosity is the highest V-level that is enabled. The zero value only enables V(0).
+	// A negative value enables every V-level.
+	Verbosity int
+	// Clock stamps each message with the time it was logged. Defaults to ectologger.SystemClock.
+	// Sinks created from a Logger use the clock of the Logger instead.
+	Clock ectologger.Clock
+}
+
+// LogSink is a logr.LogSink backed by an ectologger Logger, so libraries using logr, such as
+// controller-runtime and client-go, log through the same pipeline as the rest of the
+// application. V(0) maps to info, V(1) to debug and V(2) and above to trace. WithName names
+// the Logger with ectologger.Logger.Named, so a LevelRegistry filters the messages of named sinks.
+type LogSink struct {
+	logger ectologger.Logger
+	opts   Options
+	values []ectologger.Field
+}
+
+var _ logr.LogSink = (*LogSink)(nil)
+
+// NewLogSink returns a LogSink writing to logFunc.
+func NewLogSink(logFunc ectologger.EctoLogFunc, opts Options) *LogSink {
+	if opts.Clock == nil {
+		opts.Clock = ectologger.SystemClock
+	}
+	return NewLogSinkFromLogger(ectologger.NewEctoLogger(logFunc, ectologger.WithClock(opts.Clock)), opts)
+}
+
+// NewLogSinkFromLogger returns a LogSink logging through logger.
+func NewLogSinkFromLogger(logger ectologger.Logger, opts Options) *LogSink {
+	return &LogSink{logger: logger, opts: opts}
+}
+
+// NewLogr returns a logr.Logger writing to logFunc.
+func NewLogr(logFunc ectologger.EctoLogFunc, opts Options) logr.Logger {
+	return logr.New(NewLogSink(logFunc, opts))
+}
+
+// NewLogrFromLogger returns a logr.Logger logging through logger.
+func NewLogrFromLogger(logger ectologger.Logger, opts Options) logr.Logger {
+	return logr.New(NewLogSinkFromLogger(logger, opts))
+}
+
+// Init is called by logr with runtime information. The sink does not need it.
+func (s *LogSink) Init(info logr.RuntimeInfo) {}
+
+// Enabled reports whether the V-level is enabled.
+func (s *LogSink) Enabled(level int) bool {
+	return s.opts.Verbosity < 0 || level <= s.opts.Verbosity
+}
+
+// Info logs a non-error message at the level matching the V-level.
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.log(vLevel(level), msg, nil, keysAndValues)
+}
+
+// Error logs an error message at the error level.
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.log(ectologger.ErrorLevel, msg, err, keysAndValues)
+}
+
+// WithValues returns a new LogSink with the given keys and values added to every message.
+func (s *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	clone := *s
+	clone.values = append(append([]ectologger.Field(nil), s.values...), toFields(keysAndValues)...)
+	return &clone
+}
+
+// WithName returns a new LogSink with name appended to the logger name, separated by a dot.
+func (s *LogSink) WithName(name string) logr.LogSink {
+	clone := *s
+	clone.logger = s.logger.Named(name)
+	return &clone
+}
+
+// log logs msg through the logger with the values of the sink and keysAndValues.
+func (s *LogSink) log(level string, msg string, err error, keysAndValues []interface{}) {
+	logger := s.logger
+	fields := append(s.values[:len(s.values):len(s.values)], toFields(keysAndValues)...)
+	if len(fields) > 0 {
+		logger = logger.WithTypedFields(fields...)
+	}
+	if err != nil {
+		logger = logger.WithError(err)
+	}
+	ectologger.LogAt(logger, level, msg)
+}
+
+// vLevel maps a logr V-level to an ectologger level.
+func vLevel(level int) string {
+	switch {
+	case level <= 0:
+		return ectologger.InfoLevel
+	case level == 1:
+		return ectologger.DebugLevel
+	default:
+		return ectologger.TraceLevel
+	}
+}
+
+// toFields converts logr keys and values to typed fields. Non-string keys are formatted,
+// and a trailing key without a value is logged with a placeholder.
+func toFields(keysAndValues []interface{}) []ectologger.Field {
+	fields := make([]ectologger.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		var value interface{} = noValue
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fields = append(fields, ectologger.Any(key, value))
+	}
+	return fields
+}
+
+// GetLogrLogFunc returns a log function that logs to the provided logr logger.
+// Trace, debug and info map to V(2), V(1) and V(0), warn is logged at V(0) with a level
+// value, and error and fatal are logged with Error. A logr logger attached to the message
+// context with logr.NewContext takes precedence.
+func GetLogrLogFunc(logger logr.Logger) ectologger.EctoLogFunc {
+	return func(msg ectologger.EctoLogMessage) {
+		l := logger
+		if msg.Ctx != nil {
+			if ctxLogger, err := logr.FromContext(msg.Ctx); err == nil {
+				l = ctxLogger
+			}
+		}
+
+		switch msg.Level {
+		case ectologger.ErrorLevel, ectologger.FatalLevel:
+			// logr has no verbosity for errors, they are always passed to the sink
+			msg = msg.Resolve()
+			kv := keysAndValues(msg)
+			if msg.Level == ectologger.FatalLevel {
+				kv = append(kv, "level", ectologger.FatalLevel)
+			}
+			l.Error(msg.Err, msg.Message, kv...)
+		default:
+			v := 0
+			switch msg.Level {
+			case ectologger.DebugLevel:
+				v = 1
+			case ectologger.TraceLevel:
+				v = 2
+			}
+			l = l.V(v)
+			if !l.Enabled() {
+				return
+			}
+			msg = msg.Resolve()
+			kv := keysAndValues(msg)
+			if msg.Err != nil {
+				kv = append(kv, "error", msg.Err)
+			}
+			if msg.Level == ectologger.WarnLevel {
+				kv = append(kv, "level", ectologger.WarnLevel)
+			}
+			l.Info(msg.Message, kv...)
+		}
+	}
+}
+
+// NewLogrEctoLogger returns a new EctoLogger that logs to the provided logr logger.
+func NewLogrEctoLogger(logger logr.Logger, opts ...ectologger.Option) ectologger.Logger {
+	return ectologger.NewEctoLogger(GetLogrLogFunc(logger), opts...)
+}
+
+// keysAndValues flattens the fields of msg into logr keys and values. Map fields come
+// first in key order, followed by typed fields. Fields following a namespace are passed
+// as a single nested map value, as logr has no namespaces.
+func keysAndValues(msg ectologger.EctoLogMessage) []interface{} {
+	kv := make([]interface{}, 0, 2*(len(msg.Fields)+len(msg.TypedFields)))
+
+	keys := make([]string, 0, len(msg.Fields))
+	for k := range msg.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		kv = append(kv, k, msg.Fields[k])
+	}
+
+	for i, f := range msg.TypedFields {
+		switch f.Type {
+		case ectologger.SkipType:
+			continue
+		case ectologger.NamespaceType:
+			return append(kv, f.Key, ectologger.FieldsToMap(msg.TypedFields[i+1:]))
+		}
+		kv = append(kv, f.Key, f.Value())
+	}
+	return kv
+}
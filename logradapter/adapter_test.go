@@ -0,0 +1,129 @@
+package logradapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/Gobusters/ectologger/ectologgertest"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogSink(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	logger := NewLogr(func(msg ectologger.EctoLogMessage) {
+		captured = append(captured, msg)
+	}, Options{Verbosity: 1})
+	err := errors.New("test error")
+
+	named := logger.WithName("controller").WithName("reconciler").WithValues("namespace", "default")
+	named.Info("info", "count", 1)
+	named.V(1).Info("debug")
+	named.V(2).Info("trace is disabled")
+	named.Error(err, "failed", "odd")
+
+	require.Len(t, captured, 3)
+	assert.Equal(t, ectologger.InfoLevel, captured[0].Level)
+	assert.Equal(t, map[string]interface{}{
+		NameKey:     "controller.reconciler",
+		"namespace": "default",
+		"count":     int64(1),
+	}, captured[0].FieldMap())
+	assert.Equal(t, ectologger.DebugLevel, captured[1].Level)
+	assert.Equal(t, ectologger.ErrorLevel, captured[2].Level)
+	assert.Equal(t, err, captured[2].Err)
+	assert.Equal(t, noValue, captured[2].FieldMap()["odd"])
+}
+
+func TestLogSinkUsesClock(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	clock := ectologgertest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := NewLogr(func(msg ectologger.EctoLogMessage) {
+		captured = append(captured, msg)
+	}, Options{Clock: clock})
+
+	logger.Info("test message")
+
+	require.Len(t, captured, 1)
+	assert.Equal(t, clock.Now(), captured[0].Time)
+}
+
+func TestLogSinkVerbosity(t *testing.T) {
+	sink := NewLogSink(func(ectologger.EctoLogMessage) {}, Options{})
+	assert.True(t, sink.Enabled(0))
+	assert.False(t, sink.Enabled(1))
+
+	sink = NewLogSink(func(ectologger.EctoLogMessage) {}, Options{Verbosity: -1})
+	assert.True(t, sink.Enabled(10))
+	assert.Equal(t, ectologger.TraceLevel, vLevel(10))
+}
+
+func TestLogSinkWithValuesDoesNotShareFields(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	base := NewLogr(func(msg ectologger.EctoLogMessage) {
+		captured = append(captured, msg)
+	}, Options{}).WithValues("base", 1)
+
+	base.WithValues("a", 1).Info("a")
+	base.WithValues("b", 2).Info("b")
+
+	assert.Equal(t, map[string]interface{}{"base": int64(1), "a": int64(1)}, captured[0].FieldMap())
+	assert.Equal(t, map[string]interface{}{"base": int64(1), "b": int64(2)}, captured[1].FieldMap())
+}
+
+func newFuncrLogger(lines *[]string, verbosity int) logr.Logger {
+	return funcr.New(func(prefix, args string) {
+		*lines = append(*lines, args)
+	}, funcr.Options{Verbosity: verbosity})
+}
+
+func TestLogrEctoLogger(t *testing.T) {
+	var lines []string
+	logger := NewLogrEctoLogger(newFuncrLogger(&lines, 1))
+
+	logger.WithField("key", "value").Info("info")
+	logger.Debug("debug")
+	logger.Warn("warn")
+	logger.WithError(errors.New("test error")).Error("error")
+	GetLogrLogFunc(newFuncrLogger(&lines, 1))(ectologger.EctoLogMessage{Level: ectologger.TraceLevel, Message: "trace"})
+
+	require.Len(t, lines, 4)
+	assert.Equal(t, `"level"=0 "msg"="info" "key"="value"`, lines[0])
+	assert.Equal(t, `"level"=1 "msg"="debug"`, lines[1])
+	assert.Equal(t, `"level"=0 "msg"="warn" "level"="warn"`, lines[2])
+	assert.Equal(t, `"msg"="error" "error"="test error"`, lines[3])
+}
+
+func TestLogrEctoLoggerUsesContextLogger(t *testing.T) {
+	var defaultLines, ctxLines []string
+	ctx := logr.NewContext(context.Background(), newFuncrLogger(&ctxLines, 0))
+
+	NewLogrEctoLogger(newFuncrLogger(&defaultLines, 0)).InfoContext(ctx, "test message")
+
+	assert.Empty(t, defaultLines)
+	assert.Len(t, ctxLines, 1)
+}
+
+func TestLogSinkNamesFollowLevelRegistry(t *testing.T) {
+	registry, err := ectologger.NewLevelRegistry("controller=warn,controller.reconciler=trace")
+	require.NoError(t, err)
+	rec := ectologgertest.NewRecorder(ectologger.WithLevelRegistry(registry))
+	logger := NewLogrFromLogger(rec, Options{Verbosity: -1})
+
+	controller := logger.WithName("controller")
+	controller.Info("dropped by the registry")
+	controller.Error(errors.New("test error"), "controller error")
+	controller.WithName("reconciler").V(2).Info("reconciler trace", "key", "value")
+
+	messages := rec.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "controller error", messages[0].Message)
+	assert.Equal(t, "controller", messages[0].FieldMap()[NameKey])
+	assert.Equal(t, ectologger.TraceLevel, messages[1].Level)
+	assert.Equal(t, map[string]interface{}{NameKey: "controller.reconciler", "key": "value"}, messages[1].FieldMap())
+}
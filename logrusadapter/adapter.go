@@ -0,0 +1,120 @@
+package logrusadapter
+
+import (
+	"github.com/Gobusters/ectologger"
+	"github.com/sirupsen/logrus"
+)
+
+// toLogrusLevel maps an ectologger level to the logrus level. Unknown levels map to Info.
+func toLogrusLevel(level string) logrus.Level {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return logrus.InfoLevel // Default to Info level if parsing fails
+	}
+	return parsed
+}
+
+// fromLogrusLevel maps a logrus level to the closest ectologger level.
+func fromLogrusLevel(level logrus.Level) string {
+	switch level {
+	case logrus.TraceLevel:
+		return ectologger.TraceLevel
+	case logrus.DebugLevel:
+		return ectologger.DebugLevel
+	case logrus.InfoLevel:
+		return ectologger.InfoLevel
+	case logrus.WarnLevel:
+		return ectologger.WarnLevel
+	case logrus.ErrorLevel:
+		return ectologger.ErrorLevel
+	default:
+		return ectologger.FatalLevel
+	}
+}
+
+// GetLogrusLogFunc returns a log function that logs to the provided logrus entry.
+// Fields become logrus fields, the error is added under logrus.ErrorKey and the context
+// is attached with WithContext. Fatal messages call the logger's ExitFunc after logging,
+// like logrus' own Fatal.
+func GetLogrusLogFunc(entry *logrus.Entry) ectologger.EctoLogFunc {
+	return func(msg ectologger.EctoLogMessage) {
+		level := toLogrusLevel(msg.Level)
+
+		// Check before building fields so LogValuers only run when logrus will write the entry
+		if !entry.Logger.IsLevelEnabled(level) {
+			return
+		}
+		msg = msg.Resolve()
+
+		fieldMap := msg.FieldMap()
+		fields := make(logrus.Fields, len(fieldMap)+1)
+		for k, v := range fieldMap {
+			fields[k] = v
+		}
+		if msg.Err != nil {
+			fields[logrus.ErrorKey] = msg.Err
+		}
+
+		e := entry.WithFields(fields)
+		if msg.Ctx != nil {
+			e = e.WithContext(msg.Ctx)
+		}
+		if !msg.Time.IsZero() {
+			e = e.WithTime(msg.Time)
+		}
+
+		e.Log(level, msg.Message)
+		if level == logrus.FatalLevel {
+			entry.Logger.Exit(1)
+		}
+	}
+}
+
+// NewLogrusEctoLogger returns a new EctoLogger that logs to the provided logrus logger.
+func NewLogrusEctoLogger(logrusLogger *logrus.Logger, opts ...ectologger.Option) ectologger.Logger {
+	return ectologger.NewEctoLogger(GetLogrusLogFunc(logrus.NewEntry(logrusLogger)), opts...)
+}
+
+// Hook is a logrus hook that forwards logrus entries to an ectologger log function,
+// so logs written by legacy logrus code flow through the ectologger pipeline.
+// The log function must not write back into the logrus logger the hook is added to.
+type Hook struct {
+	logFunc ectologger.EctoLogFunc
+	levels  []logrus.Level
+}
+
+// NewHook returns a Hook forwarding entries at the given levels to logFunc.
+// When no levels are given, entries at every level are forwarded.
+func NewHook(logFunc ectologger.EctoLogFunc, levels ...logrus.Level) *Hook {
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+	return &Hook{logFunc: logFunc, levels: levels}
+}
+
+// Levels returns the levels the hook forwards.
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire converts entry to an EctoLogMessage and passes it to the log function.
+// An error stored under logrus.ErrorKey becomes the message's error.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	msg := ectologger.EctoLogMessage{
+		Level:   fromLogrusLevel(entry.Level),
+		Message: entry.Message,
+		Fields:  make(map[string]interface{}, len(entry.Data)),
+		Ctx:     entry.Context,
+		Time:    entry.Time,
+	}
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok && k == logrus.ErrorKey {
+			msg.Err = err
+			continue
+		}
+		msg.Fields[k] = v
+	}
+
+	h.logFunc(msg)
+	return nil
+}
@@ -0,0 +1,84 @@
+package logrusadapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/Gobusters/ectologger/ectologgertest"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type contextKey string
+
+func TestLogrusEctoLogger(t *testing.T) {
+	logrusLogger, hook := test.NewNullLogger()
+	logger := NewLogrusEctoLogger(logrusLogger)
+	ctx := context.WithValue(context.Background(), contextKey("key"), "value")
+	err := errors.New("test error")
+
+	logger.WithField("key", "value").
+		WithTypedFields(ectologger.Int64("count", 2)).
+		WithError(err).
+		WarnContext(ctx, "test message")
+
+	require.Len(t, hook.Entries, 1)
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "test message", entry.Message)
+	assert.Equal(t, logrus.Fields{"key": "value", "count": int64(2), logrus.ErrorKey: err}, entry.Data)
+	assert.Equal(t, ctx, entry.Context)
+}
+
+func TestLogrusEctoLoggerKeepsCallSiteTime(t *testing.T) {
+	logrusLogger, hook := test.NewNullLogger()
+	clock := ectologgertest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := NewLogrusEctoLogger(logrusLogger, ectologger.WithClock(clock))
+
+	logger.Info("test message")
+
+	require.Len(t, hook.Entries, 1)
+	assert.Equal(t, clock.Now(), hook.LastEntry().Time)
+}
+
+func TestLogrusEctoLoggerLevels(t *testing.T) {
+	logrusLogger, hook := test.NewNullLogger()
+	logrusLogger.SetLevel(logrus.InfoLevel)
+	exitCode := -1
+	logrusLogger.ExitFunc = func(code int) { exitCode = code }
+	logger := NewLogrusEctoLogger(logrusLogger)
+
+	logger.Debug("filtered")
+	logger.Error("error")
+	logger.Fatal("fatal")
+
+	require.Len(t, hook.Entries, 2)
+	assert.Equal(t, logrus.ErrorLevel, hook.Entries[0].Level)
+	assert.Equal(t, logrus.FatalLevel, hook.Entries[1].Level)
+	assert.Equal(t, 1, exitCode)
+}
+
+func TestHookForwardsEntries(t *testing.T) {
+	var captured []ectologger.EctoLogMessage
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(io.Discard)
+	logrusLogger.AddHook(NewHook(func(msg ectologger.EctoLogMessage) {
+		captured = append(captured, msg)
+	}, logrus.ErrorLevel, logrus.WarnLevel))
+	err := errors.New("test error")
+
+	logrusLogger.Info("not forwarded")
+	logrusLogger.WithField("key", "value").WithError(err).Error("test message")
+
+	require.Len(t, captured, 1)
+	assert.Equal(t, ectologger.ErrorLevel, captured[0].Level)
+	assert.Equal(t, "test message", captured[0].Message)
+	assert.Equal(t, map[string]interface{}{"key": "value"}, captured[0].Fields)
+	assert.Equal(t, err, captured[0].Err)
+}
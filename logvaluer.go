@@ -0,0 +1,139 @@
+package ectologger
+
+import (
+	"fmt"
+)
+
+// maxLogValuerDepth bounds how many LogValuers are unwrapped for a single value,
+// protecting encoders from a LogValue that returns itself.
+const maxLogValuerDepth = 100
+
+// LogValuer is implemented by values that control their own logged representation.
+// LogValue is only called when a message is actually emitted, after level filtering and
+// sampling, so it can be used to defer expensive work such as serializing a large config.
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// LogValuerFunc adapts an ordinary function to a LogValuer so a value can be computed lazily.
+type LogValuerFunc func() interface{}
+
+// LogValue calls f.
+func (f LogValuerFunc) LogValue() interface{} {
+	return f()
+}
+
+// resolveValue unwraps v until it is no longer a LogValuer.
+// A panicking LogValue is reported in place of the value rather than crashing the caller.
+func resolveValue(v interface{}) (resolved interface{}) {
+	for i := 0; i < maxLogValuerDepth; i++ {
+		lv, ok := v.(LogValuer)
+		if !ok {
+			return v
+		}
+		v = callLogValue(lv)
+	}
+	return fmt.Sprintf("!ERROR: LogValue exceeded max depth of %d", maxLogValuerDepth)
+}
+
+// callLogValue calls LogValue, recovering any panic as the logged value.
+func callLogValue(lv LogValuer) (value interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			value = fmt.Sprintf("!PANIC: LogValue panicked: %v", r)
+		}
+	}()
+	return lv.LogValue()
+}
+
+// Resolve returns the field with any LogValuer replaced by the value it resolves to,
+// including values nested inside objects and arrays.
+func (f Field) Resolve() Field {
+	resolved, _ := resolveField(f)
+	return resolved
+}
+
+// resolveField resolves f and reports whether anything changed.
+func resolveField(f Field) (Field, bool) {
+	switch f.Type {
+	case AnyType, ErrorType, TimeType:
+		if _, ok := f.Interface.(LogValuer); ok {
+			return Any(f.Key, resolveValue(f.Interface)), true
+		}
+	case ObjectType:
+		fields, _ := f.Interface.([]Field)
+		if resolved, changed := resolveFields(fields); changed {
+			return Object(f.Key, resolved...), true
+		}
+	case ArrayType:
+		values, _ := f.Interface.([]interface{})
+		if resolved, changed := resolveValues(values); changed {
+			return Array(f.Key, resolved...), true
+		}
+	}
+	return f, false
+}
+
+// resolveFields resolves every field, only allocating a new slice when a field changed.
+func resolveFields(fields []Field) ([]Field, bool) {
+	var resolved []Field
+	for i, f := range fields {
+		r, changed := resolveField(f)
+		if resolved == nil {
+			if !changed {
+				continue
+			}
+			resolved = make([]Field, len(fields))
+			copy(resolved, fields[:i])
+		}
+		resolved[i] = r
+	}
+	if resolved == nil {
+		return fields, false
+	}
+	return resolved, true
+}
+
+// resolveValues resolves every value, only allocating a new slice when a value was a LogValuer.
+func resolveValues(values []interface{}) ([]interface{}, bool) {
+	for i, v := range values {
+		if _, ok := v.(LogValuer); !ok {
+			continue
+		}
+		resolved := make([]interface{}, len(values))
+		copy(resolved, values[:i])
+		for j := i; j < len(values); j++ {
+			resolved[j] = resolveValue(values[j])
+		}
+		return resolved, true
+	}
+	return values, false
+}
+
+// Resolve returns the message with every LogValuer in its map and typed fields resolved.
+// The fields of the original message are never mutated. A resolved message holds no
+// LogValuers, so resolving it again is a cheap no-op: encoders and processors can all call
+// Resolve while each LogValue still runs exactly once, including those of fields added
+// after an earlier Resolve.
+func (msg EctoLogMessage) Resolve() EctoLogMessage {
+	var fields map[string]interface{}
+	for k, v := range msg.Fields {
+		if _, ok := v.(LogValuer); !ok {
+			continue
+		}
+		if fields == nil {
+			// Copy before writing, the map is shared with the logger that produced the message
+			fields = make(map[string]interface{}, len(msg.Fields))
+			for k, v := range msg.Fields {
+				fields[k] = v
+			}
+		}
+		fields[k] = resolveValue(v)
+	}
+	if fields != nil {
+		msg.Fields = fields
+	}
+
+	msg.TypedFields, _ = resolveFields(msg.TypedFields)
+	return msg
+}
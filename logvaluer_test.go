@@ -0,0 +1,75 @@
+package ectologger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingValuer struct {
+	calls int
+}
+
+func (c *countingValuer) LogValue() interface{} {
+	c.calls++
+	return "resolved"
+}
+
+func TestEctoLogMessageResolve(t *testing.T) {
+	mapValuer := &countingValuer{}
+	typedValuer := &countingValuer{}
+	fields := map[string]interface{}{"lazy": mapValuer, "plain": "value"}
+
+	msg := EctoLogMessage{
+		Fields: fields,
+		TypedFields: []Field{
+			Any("lazy", typedValuer),
+			Object("object", Any("nested", LogValuerFunc(func() interface{} { return 1 }))),
+			Array("array", LogValuerFunc(func() interface{} { return "element" })),
+		},
+	}
+
+	resolved := msg.Resolve().Resolve()
+
+	assert.Equal(t, 1, mapValuer.calls)
+	assert.Equal(t, 1, typedValuer.calls)
+	assert.Equal(t, "resolved", resolved.Fields["lazy"])
+	assert.Equal(t, "value", resolved.Fields["plain"])
+	assert.Equal(t, String("lazy", "resolved"), resolved.TypedFields[0])
+	assert.Equal(t, map[string]interface{}{"nested": int64(1)}, resolved.TypedFields[1].Value())
+	assert.Equal(t, []interface{}{"element"}, resolved.TypedFields[2].Value())
+
+	// The logger's fields must not be mutated by resolution
+	assert.Same(t, mapValuer, fields["lazy"])
+}
+
+func TestLogValuerSkippedWhenDropped(t *testing.T) {
+	valuer := &countingValuer{}
+	var emitted []EctoLogMessage
+	logger := NewEctoLogger(func(msg EctoLogMessage) {
+		if msg.Level == "debug" {
+			return
+		}
+		emitted = append(emitted, msg.Resolve())
+	})
+
+	logger.WithField("lazy", valuer).Debug("dropped")
+	assert.Equal(t, 0, valuer.calls)
+
+	logger.WithField("lazy", valuer).Info("emitted")
+	assert.Equal(t, 1, valuer.calls)
+	assert.Equal(t, "resolved", emitted[0].Fields["lazy"])
+}
+
+type selfValuer struct{}
+
+func (s selfValuer) LogValue() interface{} { return s }
+
+type panicValuer struct{}
+
+func (panicValuer) LogValue() interface{} { panic("boom") }
+
+func TestResolveValueGuards(t *testing.T) {
+	assert.Contains(t, resolveValue(selfValuer{}), "max depth")
+	assert.Contains(t, resolveValue(panicValuer{}), "boom")
+}
@@ -0,0 +1,179 @@
+package ectologger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LoggerNameKey is the key of the field holding the dotted name of a logger built by Named.
+const LoggerNameKey = "logger"
+
+// LevelRegistry holds minimum levels for named loggers, keyed by name prefix. A prefix applies
+// to the logger with that name and to every logger below it, so "db" covers "db" and "db.pool"
+// but not "dbx". When several prefixes match, the longest wins; "*" matches every name,
+// including unnamed loggers. Messages of loggers no rule matches are not filtered.
+//
+// Loggers cache the level resolved for their name, so checking it costs the same however many
+// rules there are. Changing the rules invalidates the caches, and the loggers already created
+// pick up the new levels with their next message.
+type LevelRegistry struct {
+	mu         sync.RWMutex
+	rules      map[string]string // Minimum level per prefix
+	prefixes   []string          // Keys of rules, longest first
+	generation atomic.Uint64     // Incremented whenever the rules change
+}
+
+// NewLevelRegistry returns a LevelRegistry with the rules of spec, see Set.
+func NewLevelRegistry(spec string) (*LevelRegistry, error) {
+	r := &LevelRegistry{rules: map[string]string{}}
+	if err := r.Set(spec); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WithLevelRegistry filters the messages of the logger and of the loggers derived from it
+// with the level the registry holds for their name.
+func WithLevelRegistry(registry *LevelRegistry) Option {
+	return func(l *EctoLogger) {
+		l.levels = registry
+	}
+}
+
+// Set replaces all rules with those of spec, a comma separated list of prefix=level pairs such
+// as "db=debug,http=warn,*=info". The rules are left unchanged if spec is invalid.
+func (r *LevelRegistry) Set(spec string) error {
+	rules := map[string]string{}
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		prefix, level, ok := strings.Cut(rule, "=")
+		prefix, level = strings.TrimSpace(prefix), strings.TrimSpace(level)
+		if !ok || prefix == "" {
+			return fmt.Errorf("ectologger: invalid level rule %q, expected prefix=level", rule)
+		}
+		if !validLevel(level) {
+			return fmt.Errorf("ectologger: invalid level rule %q: unknown level %q", rule, level)
+		}
+		rules[prefix] = level
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = rules
+	r.update()
+	return nil
+}
+
+// SetLevel sets the minimum level of the loggers named prefix or below it. An empty level
+// removes the rule.
+func (r *LevelRegistry) SetLevel(prefix, level string) error {
+	if level != "" && !validLevel(level) {
+		return fmt.Errorf("ectologger: unknown level %q", level)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rules := make(map[string]string, len(r.rules)+1)
+	for p, l := range r.rules {
+		rules[p] = l
+	}
+	if level == "" {
+		delete(rules, prefix)
+	} else {
+		rules[prefix] = level
+	}
+	r.rules = rules
+	r.update()
+	return nil
+}
+
+// Level returns the minimum level of the logger with the given name, or "" if no rule matches.
+func (r *LevelRegistry) Level(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, prefix := range r.prefixes {
+		if prefix == "*" || name == prefix || strings.HasPrefix(name, prefix+".") {
+			return r.rules[prefix]
+		}
+	}
+	return ""
+}
+
+// String returns the rules in the format accepted by Set.
+func (r *LevelRegistry) String() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := make([]string, len(r.prefixes))
+	for i, prefix := range r.prefixes {
+		rules[i] = prefix + "=" + r.rules[prefix]
+	}
+	return strings.Join(rules, ",")
+}
+
+// update sorts the prefixes after the rules changed and invalidates the cached levels.
+// It must be called with mu held.
+func (r *LevelRegistry) update() {
+	r.prefixes = r.prefixes[:0:0]
+	for prefix := range r.rules {
+		r.prefixes = append(r.prefixes, prefix)
+	}
+	sort.Slice(r.prefixes, func(i, j int) bool {
+		a, b := r.prefixes[i], r.prefixes[j]
+		// "*" matches everything, so it is tried last
+		if (a == "*") != (b == "*") {
+			return b == "*"
+		}
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	r.generation.Add(1)
+}
+
+// levelGate filters the messages of a named logger with the level its registry holds for it.
+// It is shared by the loggers derived from the named logger, which have the same name.
+type levelGate struct {
+	registry *LevelRegistry
+	name     string
+	resolved atomic.Pointer[resolvedLevel]
+}
+
+// resolvedLevel is the level of a name in a given generation of the registry.
+type resolvedLevel struct {
+	generation uint64
+	level      string
+}
+
+// gateLevels returns next wrapped to drop the messages below the level registry holds for
+// name, or next itself when there is no registry.
+func gateLevels(next EctoLogFunc, registry *LevelRegistry, name string) EctoLogFunc {
+	if registry == nil {
+		return next
+	}
+	g := &levelGate{registry: registry, name: name}
+	return func(msg EctoLogMessage) {
+		if g.enabled(msg.Level) {
+			next(msg)
+		}
+	}
+}
+
+// enabled reports whether messages at level pass the gate, resolving the level of the name
+// again only when the registry changed.
+func (g *levelGate) enabled(level string) bool {
+	generation := g.registry.generation.Load()
+	resolved := g.resolved.Load()
+	if resolved == nil || resolved.generation != generation {
+		// Reading the generation first means a concurrent change is at worst resolved again
+		resolved = &resolvedLevel{generation: generation, level: g.registry.Level(g.name)}
+		g.resolved.Store(resolved)
+	}
+	return resolved.level == "" || LevelEnabled(level, resolved.level)
+}
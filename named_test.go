@@ -0,0 +1,130 @@
+package ectologger
+
+import (
+	"flag"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ flag.Value = (*LevelRegistry)(nil)
+
+func TestNamedJoinsNames(t *testing.T) {
+	rec := &messageRecorder{}
+	db := NewEctoLogger(rec.Log).Named("db")
+
+	db.Named("pool").WithGroup("stats").WithField("open", 3).Info("pool stats")
+	db.Info("connected")
+
+	require.Len(t, rec.messages, 2)
+	assert.Equal(t, map[string]interface{}{
+		LoggerNameKey: "db.pool",
+		"stats":       map[string]interface{}{"open": int64(3)},
+	}, rec.messages[0].FieldMap())
+	assert.Equal(t, map[string]interface{}{LoggerNameKey: "db"}, rec.messages[1].FieldMap())
+}
+
+func TestNamedReplacesNameRegardlessOfCollisionPolicy(t *testing.T) {
+	var reported error
+	SetErrorHandler(func(err error) { reported = err })
+	defer SetErrorHandler(DefaultErrorHandler)
+
+	rec := &messageRecorder{}
+	NewEctoLogger(rec.Log, WithCollisionPolicy(CollisionReport)).Named("db").Named("pool").Info("test message")
+
+	assert.NoError(t, reported)
+	assert.Equal(t, "db.pool", rec.messages[0].FieldMap()[LoggerNameKey])
+}
+
+func TestLevelRegistryFiltersByNamePrefix(t *testing.T) {
+	registry, err := NewLevelRegistry("db=debug, http=warn, *=info")
+	require.NoError(t, err)
+	rec := &messageRecorder{}
+	logger := NewEctoLogger(rec.Log, WithLevelRegistry(registry))
+
+	logger.Named("db").Named("pool").Debug("db.pool debug")
+	logger.Named("http").Info("http info")
+	logger.Named("http").Warn("http warn")
+	logger.Named("dbx").Debug("dbx debug")
+	logger.Named("dbx").Info("dbx info")
+	logger.Debug("root debug")
+	logger.WithField("key", "value").Info("root info")
+
+	assert.Equal(t, []string{"db.pool debug", "http warn", "dbx info", "root info"}, rec.Messages())
+}
+
+func TestLevelRegistryLongestPrefixWins(t *testing.T) {
+	registry, err := NewLevelRegistry("*=error,db=warn,db.pool=debug")
+	require.NoError(t, err)
+
+	assert.Equal(t, DebugLevel, registry.Level("db.pool.conn"))
+	assert.Equal(t, WarnLevel, registry.Level("db.cache"))
+	assert.Equal(t, ErrorLevel, registry.Level("http"))
+	assert.Equal(t, ErrorLevel, registry.Level(""))
+	assert.Equal(t, "db.pool=debug,db=warn,*=error", registry.String())
+
+	registry, err = NewLevelRegistry("db=warn")
+	require.NoError(t, err)
+	assert.Equal(t, "", registry.Level("http"))
+}
+
+func TestLevelRegistryUpdatesExistingLoggers(t *testing.T) {
+	registry, err := NewLevelRegistry("db=info")
+	require.NoError(t, err)
+	rec := &messageRecorder{}
+	pool := NewEctoLogger(rec.Log, WithLevelRegistry(registry)).Named("db").Named("pool").WithField("key", "value")
+
+	pool.Debug("dropped")
+	require.NoError(t, registry.SetLevel("db.pool", DebugLevel))
+	pool.Debug("logged after SetLevel")
+	require.NoError(t, registry.Set("*=error"))
+	pool.Warn("dropped after Set")
+	require.NoError(t, registry.SetLevel("*", ""))
+	pool.Debug("logged without rules")
+
+	assert.Equal(t, []string{"logged after SetLevel", "logged without rules"}, rec.Messages())
+}
+
+func TestLevelRegistryRejectsInvalidRules(t *testing.T) {
+	registry, err := NewLevelRegistry("db=debug")
+	require.NoError(t, err)
+
+	assert.EqualError(t, registry.Set("db=debug,http"), `ectologger: invalid level rule "http", expected prefix=level`)
+	assert.EqualError(t, registry.Set("http=loud"), `ectologger: invalid level rule "http=loud": unknown level "loud"`)
+	assert.EqualError(t, registry.SetLevel("http", "loud"), `ectologger: unknown level "loud"`)
+	assert.Equal(t, "db=debug", registry.String())
+
+	_, err = NewLevelRegistry("=debug")
+	assert.Error(t, err)
+}
+
+func TestLevelRegistryConcurrentUpdates(t *testing.T) {
+	registry, err := NewLevelRegistry("*=info")
+	require.NoError(t, err)
+	logger := NewEctoLogger(func(EctoLogMessage) {}, WithLevelRegistry(registry)).Named("db")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				logger.Debug("test message")
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		level := DebugLevel
+		if i%2 == 0 {
+			level = WarnLevel
+		}
+		require.NoError(t, registry.SetLevel("db", level))
+	}
+	wg.Wait()
+
+	rec := &messageRecorder{}
+	NewEctoLogger(rec.Log, WithLevelRegistry(registry)).Named("db").Debug("test message")
+	assert.Len(t, rec.messages, 1)
+}
@@ -0,0 +1,39 @@
+package ectologger
+
+import (
+	"context"
+)
+
+// nopLogger is a Logger that discards everything. Logger.V returns it for disabled levels;
+// as an empty struct it is stored in the interface without allocating.
+type nopLogger struct{}
+
+func (nopLogger) WithFields(map[string]interface{}) Logger { return nopLogger{} }
+func (nopLogger) WithField(string, interface{}) Logger     { return nopLogger{} }
+func (nopLogger) WithTypedFields(...Field) Logger          { return nopLogger{} }
+func (nopLogger) WithGroup(string) Logger                  { return nopLogger{} }
+func (nopLogger) Named(string) Logger                      { return nopLogger{} }
+func (nopLogger) V(int) Logger                             { return nopLogger{} }
+func (nopLogger) WithContext(context.Context) Logger       { return nopLogger{} }
+func (nopLogger) WithError(error) Logger                   { return nopLogger{} }
+
+func (nopLogger) Debug(string)                                  {}
+func (nopLogger) Debugf(string, ...any)                         {}
+func (nopLogger) DebugContext(context.Context, string)          {}
+func (nopLogger) DebugContextf(context.Context, string, ...any) {}
+func (nopLogger) Info(string)                                   {}
+func (nopLogger) Infof(string, ...any)                          {}
+func (nopLogger) InfoContext(context.Context, string)           {}
+func (nopLogger) InfoContextf(context.Context, string, ...any)  {}
+func (nopLogger) Warn(string)                                   {}
+func (nopLogger) Warnf(string, ...any)                          {}
+func (nopLogger) WarnContext(context.Context, string)           {}
+func (nopLogger) WarnContextf(context.Context, string, ...any)  {}
+func (nopLogger) Error(string)                                  {}
+func (nopLogger) Errorf(string, ...any)                         {}
+func (nopLogger) ErrorContext(context.Context, string)          {}
+func (nopLogger) ErrorContextf(context.Context, string, ...any) {}
+func (nopLogger) Fatal(string)                                  {}
+func (nopLogger) Fatalf(string, ...any)                         {}
+func (nopLogger) FatalContext(context.Context, string)          {}
+func (nopLogger) FatalContextf(context.Context, string, ...any) {}
@@ -0,0 +1,188 @@
+package ectologger
+
+// RedactedValue replaces the value of redacted fields.
+const RedactedValue = "[REDACTED]"
+
+// Processor is a step of a log pipeline. It receives each message together with the rest of
+// the pipeline and may mutate or enrich the message before passing it on, drop it by not
+// calling next, or split it by calling next more than once.
+type Processor func(msg EctoLogMessage, next EctoLogFunc)
+
+// Chain returns a log function that runs msg through the processors in order before passing it
+// to logFunc. The same processors can be chained in front of any backend.
+func Chain(logFunc EctoLogFunc, processors ...Processor) EctoLogFunc {
+	for i := len(processors) - 1; i >= 0; i-- {
+		processor, next := processors[i], logFunc
+		logFunc = func(msg EctoLogMessage) {
+			processor(msg, next)
+		}
+	}
+	return logFunc
+}
+
+// WithProcessors adds processors that every message logged through the logger passes
+// through, in order, before reaching its log function.
+func WithProcessors(processors ...Processor) Option {
+	return func(l *EctoLogger) {
+		l.processors = append(l.processors, processors...)
+	}
+}
+
+// Mutate returns a processor that replaces each message with the result of fn.
+func Mutate(fn func(msg EctoLogMessage) EctoLogMessage) Processor {
+	return func(msg EctoLogMessage, next EctoLogFunc) {
+		next(fn(msg))
+	}
+}
+
+// AddFields returns a processor that adds static fields to the root of every message.
+func AddFields(fields ...Field) Processor {
+	return func(msg EctoLogMessage, next EctoLogFunc) {
+		// Prepend so the fields are added before any group the message opened
+		typed := make([]Field, 0, len(fields)+len(msg.TypedFields))
+		typed = append(typed, fields...)
+		msg.TypedFields = append(typed, msg.TypedFields...)
+		next(msg)
+	}
+}
+
+// RenameKeys returns a processor that renames root level fields from the keys of renames to their values.
+func RenameKeys(renames map[string]string) Processor {
+	return func(msg EctoLogMessage, next EctoLogFunc) {
+		var fields map[string]interface{}
+		for from, to := range renames {
+			if v, ok := msg.Fields[from]; ok {
+				if fields == nil {
+					fields = copyFields(msg.Fields, 0)
+				}
+				delete(fields, from)
+				fields[to] = v
+			}
+		}
+		if fields != nil {
+			msg.Fields = fields
+		}
+
+		var typed []Field
+		for i, f := range msg.TypedFields {
+			if f.Type == NamespaceType {
+				break
+			}
+			to, ok := renames[f.Key]
+			if !ok {
+				continue
+			}
+			if typed == nil {
+				typed = append([]Field(nil), msg.TypedFields...)
+			}
+			typed[i].Key = to
+		}
+		if typed != nil {
+			msg.TypedFields = typed
+		}
+		next(msg)
+	}
+}
+
+// DropIf returns a processor that drops the messages predicate returns true for.
+func DropIf(predicate func(msg EctoLogMessage) bool) Processor {
+	return func(msg EctoLogMessage, next EctoLogFunc) {
+		if predicate(msg) {
+			return
+		}
+		next(msg)
+	}
+}
+
+// MinLevel returns a processor that drops messages below level.
+func MinLevel(level string) Processor {
+	return DropIf(func(msg EctoLogMessage) bool {
+		return !LevelEnabled(msg.Level, level)
+	})
+}
+
+// OverrideLevel returns a processor that changes the level of the messages match returns true for.
+func OverrideLevel(level string, match func(msg EctoLogMessage) bool) Processor {
+	return func(msg EctoLogMessage, next EctoLogFunc) {
+		if match(msg) {
+			msg.Level = level
+		}
+		next(msg)
+	}
+}
+
+// Redact returns a processor that replaces the value of every field named by keys with
+// RedactedValue, at any nesting depth. LogValuers are resolved first so their output is redacted too.
+func Redact(keys ...string) Processor {
+	redacted := redactSet(keys)
+	return func(msg EctoLogMessage, next EctoLogFunc) {
+		msg = msg.Resolve()
+		msg.Fields = redactMap(msg.Fields, redacted)
+		msg.TypedFields = redactFields(msg.TypedFields, redacted)
+		next(msg)
+	}
+}
+
+// RedactFields returns fields with the value of every field named by keys replaced with
+// RedactedValue, at any nesting depth, without modifying fields. It is the field level
+// counterpart of Redact, for values such as headers that are redacted before they are logged.
+func RedactFields(fields []Field, keys ...string) []Field {
+	return redactFields(fields, redactSet(keys))
+}
+
+// redactSet returns keys as a set.
+func redactSet(keys []string) map[string]struct{} {
+	redacted := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		redacted[k] = struct{}{}
+	}
+	return redacted
+}
+
+// redactMap returns fields with redacted keys replaced, copying only when something changed.
+func redactMap(fields map[string]interface{}, redacted map[string]struct{}) map[string]interface{} {
+	var result map[string]interface{}
+	for k, v := range fields {
+		replacement := v
+		if _, ok := redacted[k]; ok {
+			replacement = RedactedValue
+		} else if nested, ok := v.(map[string]interface{}); ok {
+			replacement = redactMap(nested, redacted)
+		} else {
+			continue
+		}
+		if result == nil {
+			result = copyFields(fields, 0)
+		}
+		result[k] = replacement
+	}
+	if result == nil {
+		return fields
+	}
+	return result
+}
+
+// redactFields returns fields with redacted keys replaced, copying the slice before writing.
+func redactFields(fields []Field, redacted map[string]struct{}) []Field {
+	result := fields
+	copied := false
+	for i, f := range fields {
+		var replacement Field
+		if _, ok := redacted[f.Key]; ok && f.Type != NamespaceType && f.Type != SkipType {
+			replacement = String(f.Key, RedactedValue)
+		} else if f.Type == ObjectType {
+			nested, _ := f.Interface.([]Field)
+			replacement = Object(f.Key, redactFields(nested, redacted)...)
+		} else if nested, ok := f.Interface.(map[string]interface{}); ok && f.Type == AnyType {
+			replacement = Any(f.Key, redactMap(nested, redacted))
+		} else {
+			continue
+		}
+		if !copied {
+			result = append([]Field(nil), fields...)
+			copied = true
+		}
+		result[i] = replacement
+	}
+	return result
+}
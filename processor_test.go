@@ -0,0 +1,108 @@
+package ectologger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainRunsProcessorsInOrder(t *testing.T) {
+	rec := &messageRecorder{}
+	var order []string
+	step := func(name string) Processor {
+		return func(msg EctoLogMessage, next EctoLogFunc) {
+			order = append(order, name)
+			next(msg)
+		}
+	}
+
+	Chain(rec.Log, step("first"), step("second"))(EctoLogMessage{Message: "test message"})
+
+	assert.Equal(t, []string{"first", "second"}, order)
+	assert.Equal(t, []string{"test message"}, rec.Messages())
+}
+
+func TestProcessorCanSplitMessages(t *testing.T) {
+	rec := &messageRecorder{}
+	split := func(msg EctoLogMessage, next EctoLogFunc) {
+		next(msg)
+		msg.Message += " (copy)"
+		next(msg)
+	}
+
+	NewEctoLogger(rec.Log, WithProcessors(split)).Info("test message")
+
+	assert.Equal(t, []string{"test message", "test message (copy)"}, rec.Messages())
+}
+
+func TestHelperProcessors(t *testing.T) {
+	rec := &messageRecorder{}
+	logger := NewEctoLogger(rec.Log, WithProcessors(
+		MinLevel(InfoLevel),
+		DropIf(func(msg EctoLogMessage) bool { return msg.Message == "health check" }),
+		OverrideLevel(WarnLevel, func(msg EctoLogMessage) bool { return msg.Fields["slow"] == true }),
+		AddFields(String("service", "api")),
+		RenameKeys(map[string]string{"msg_id": "message_id"}),
+	))
+
+	logger.Debug("debug")
+	logger.Info("health check")
+	logger.WithField("slow", true).WithGroup("db").WithField("msg_id", 2).Info("query")
+	logger.WithTypedFields(Int64("msg_id", 1)).Info("typed")
+
+	require.Len(t, rec.messages, 2)
+	assert.Equal(t, WarnLevel, rec.messages[0].Level)
+	assert.Equal(t, map[string]interface{}{
+		"service": "api",
+		"slow":    true,
+		"db":      map[string]interface{}{"msg_id": int64(2)},
+	}, rec.messages[0].FieldMap())
+	assert.Equal(t, map[string]interface{}{"service": "api", "message_id": int64(1)}, rec.messages[1].FieldMap())
+}
+
+func TestRedact(t *testing.T) {
+	rec := &messageRecorder{}
+	valuer := &countingValuer{}
+	fields := map[string]interface{}{
+		"password": "secret",
+		"nested":   map[string]interface{}{"token": "secret", "user": "jane"},
+		"lazy":     valuer,
+	}
+	logger := NewEctoLogger(rec.Log, WithProcessors(Redact("password", "token")))
+
+	logger.WithFields(fields).
+		WithTypedFields(Object("auth", String("token", "secret"))).
+		WithGroup("db").
+		WithTypedFields(String("password", "secret")).
+		Info("test message")
+
+	require.Len(t, rec.messages, 1)
+	msg := rec.messages[0]
+	assert.Equal(t, map[string]interface{}{
+		"password": RedactedValue,
+		"nested":   map[string]interface{}{"token": RedactedValue, "user": "jane"},
+		"lazy":     "resolved",
+		"auth":     map[string]interface{}{"token": RedactedValue},
+		"db":       map[string]interface{}{"password": RedactedValue},
+	}, msg.FieldMap())
+
+	// Encoders resolving again must not call LogValue a second time
+	msg.Resolve()
+	assert.Equal(t, 1, valuer.calls)
+	assert.Equal(t, "secret", fields["password"])
+}
+
+func TestRedactFields(t *testing.T) {
+	fields := []Field{
+		String("user", "jane"),
+		Object("headers", String("Authorization", "Bearer secret"), String("Accept", "*/*")),
+	}
+
+	redacted := RedactFields(fields, "Authorization")
+	assert.Equal(t, map[string]interface{}{
+		"user":    "jane",
+		"headers": map[string]interface{}{"Authorization": RedactedValue, "Accept": "*/*"},
+	}, FieldsToMap(redacted))
+	assert.Equal(t, "Bearer secret", FieldsToMap(fields)["headers"].(map[string]interface{})["Authorization"])
+}
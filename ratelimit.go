@@ -0,0 +1,247 @@
+package ectologger
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultMaxRateLimitKeys bounds the number of per key buckets a RateLimiter keeps.
+const defaultMaxRateLimitKeys = 10000
+
+// RateLimiterOptions configures a RateLimiter. A rate of zero disables the corresponding bucket.
+type RateLimiterOptions struct {
+	// Rate is the number of messages per second allowed across all keys.
+	Rate float64
+	// Burst is the number of messages the global bucket can hold. Defaults to Rate, at least one.
+	Burst int
+	// KeyFunc selects the per key bucket of a message, e.g. the value of a tenant field.
+	// Per key limiting is disabled when KeyFunc is nil.
+	KeyFunc func(msg EctoLogMessage) string
+	// KeyRate is the number of messages per second allowed for each key.
+	KeyRate float64
+	// KeyBurst is the number of messages each per key bucket can hold. Defaults to KeyRate, at least one.
+	KeyBurst int
+	// MaxKeys bounds the number of per key buckets kept in memory. Defaults to 10000.
+	MaxKeys int
+	// Clock tells the current time. Defaults to SystemClock.
+	Clock Clock
+}
+
+// RateLimiter is a log function wrapper that caps log volume with token buckets, one global
+// bucket and optionally one per key. Messages are dropped while a bucket is empty, and the
+// first message let through after it refills is preceded by a line reporting how many
+// messages were suppressed.
+type RateLimiter struct {
+	next EctoLogFunc
+	opts RateLimiterOptions
+
+	mu       sync.Mutex
+	global   *tokenBucket
+	keys     map[string]*tokenBucket
+	cleared  map[string]int // Suppressed counts of the key buckets cleared to bound memory, not yet reported
+	overflow int            // Suppressed counts of cleared keys beyond MaxKeys, reported without a key
+}
+
+// tokenBucket holds the state of one bucket.
+type tokenBucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+}
+
+// NewRateLimiter returns a RateLimiter that passes the messages it allows to next.
+func NewRateLimiter(next EctoLogFunc, opts RateLimiterOptions) *RateLimiter {
+	if opts.Burst <= 0 {
+		opts.Burst = max(1, int(opts.Rate))
+	}
+	if opts.KeyBurst <= 0 {
+		opts.KeyBurst = max(1, int(opts.KeyRate))
+	}
+	if opts.MaxKeys <= 0 {
+		opts.MaxKeys = defaultMaxRateLimitKeys
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+
+	l := &RateLimiter{next: next, opts: opts, keys: map[string]*tokenBucket{}, cleared: map[string]int{}}
+	if opts.Rate > 0 {
+		l.global = &tokenBucket{tokens: float64(opts.Burst), last: opts.Clock.Now()}
+	}
+	return l
+}
+
+// Log logs msg through the wrapped log function if both its key bucket and the global bucket have a token.
+// A message dropped by one bucket does not use up a token of the other. The lines reporting
+// suppressed messages are only logged ahead of an allowed message and take no token, so they
+// add at most one line per bucket to each allowed message.
+// It has the signature of an EctoLogFunc so l.Log can be passed to NewEctoLogger.
+func (l *RateLimiter) Log(msg EctoLogMessage) {
+	key, keyed := "", l.opts.KeyFunc != nil && l.opts.KeyRate > 0
+	if keyed {
+		key = l.opts.KeyFunc(msg)
+	}
+	now := l.opts.Clock.Now()
+
+	var summaries []EctoLogMessage
+	l.mu.Lock()
+	var bucket *tokenBucket
+	allowed := true
+	if keyed {
+		bucket = l.keyBucket(key, now)
+		if allowed = bucket.available(now, l.opts.KeyRate, l.opts.KeyBurst); !allowed {
+			bucket.suppressed++
+		}
+	}
+	if allowed && l.global != nil {
+		if allowed = l.global.available(now, l.opts.Rate, l.opts.Burst); !allowed {
+			l.global.suppressed++
+		}
+	}
+	if allowed {
+		if bucket != nil {
+			bucket.tokens--
+		}
+		if l.global != nil {
+			l.global.tokens--
+		}
+		summaries = l.takeCleared(now)
+		if bucket != nil && bucket.suppressed > 0 {
+			summaries = append(summaries, suppressedMessage(bucket.suppressed, key, true, now))
+			bucket.suppressed = 0
+		}
+		if l.global != nil && l.global.suppressed > 0 {
+			summaries = append(summaries, suppressedMessage(l.global.suppressed, "", false, now))
+			l.global.suppressed = 0
+		}
+	}
+	l.mu.Unlock()
+
+	// Log outside the lock so a slow sink does not block other goroutines from being limited
+	for _, summary := range summaries {
+		l.next(summary)
+	}
+	if allowed {
+		l.next(msg)
+	}
+}
+
+// Flush emits the suppressed counts of every bucket that has dropped messages since its last report.
+func (l *RateLimiter) Flush() {
+	now := l.opts.Clock.Now()
+	l.mu.Lock()
+	summaries := l.takeCleared(now)
+	for key, bucket := range l.keys {
+		if bucket.suppressed > 0 {
+			summaries = append(summaries, suppressedMessage(bucket.suppressed, key, true, now))
+			bucket.suppressed = 0
+		}
+	}
+	if l.global != nil && l.global.suppressed > 0 {
+		summaries = append(summaries, suppressedMessage(l.global.suppressed, "", false, now))
+		l.global.suppressed = 0
+	}
+	l.mu.Unlock()
+
+	for _, summary := range summaries {
+		l.next(summary)
+	}
+}
+
+// keyBucket returns the bucket of key, creating it full. l.mu must be held.
+func (l *RateLimiter) keyBucket(key string, now time.Time) *tokenBucket {
+	bucket, ok := l.keys[key]
+	if ok {
+		return bucket
+	}
+	if len(l.keys) >= l.opts.MaxKeys {
+		l.pruneKeys(now)
+	}
+	bucket = &tokenBucket{tokens: float64(l.opts.KeyBurst), last: now}
+	l.keys[key] = bucket
+	return bucket
+}
+
+// pruneKeys forgets buckets that have refilled and have nothing to report, as they behave
+// exactly like a new bucket. If every bucket is busy the map is cleared to bound memory, and
+// the suppressed counts of the cleared buckets are kept until the next allowed message
+// reports them. l.mu must be held.
+func (l *RateLimiter) pruneKeys(now time.Time) {
+	for key, bucket := range l.keys {
+		bucket.refill(now, l.opts.KeyRate, l.opts.KeyBurst)
+		if bucket.suppressed == 0 && bucket.tokens >= float64(l.opts.KeyBurst) {
+			delete(l.keys, key)
+		}
+	}
+	if len(l.keys) < l.opts.MaxKeys {
+		return
+	}
+
+	for key, bucket := range l.keys {
+		if bucket.suppressed == 0 {
+			continue
+		}
+		// The pending counts are bounded like the buckets, further keys are only counted
+		if _, ok := l.cleared[key]; ok || len(l.cleared) < l.opts.MaxKeys {
+			l.cleared[key] += bucket.suppressed
+		} else {
+			l.overflow += bucket.suppressed
+		}
+	}
+	l.keys = map[string]*tokenBucket{}
+}
+
+// takeCleared returns the summaries of the suppressed counts of cleared key buckets, in key
+// order, and forgets the counts. l.mu must be held.
+func (l *RateLimiter) takeCleared(now time.Time) []EctoLogMessage {
+	if len(l.cleared) == 0 && l.overflow == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(l.cleared))
+	for key := range l.cleared {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	summaries := make([]EctoLogMessage, 0, len(keys)+1)
+	for _, key := range keys {
+		summaries = append(summaries, suppressedMessage(l.cleared[key], key, true, now))
+	}
+	if l.overflow > 0 {
+		summaries = append(summaries, suppressedMessage(l.overflow, "", false, now))
+	}
+	l.cleared, l.overflow = map[string]int{}, 0
+	return summaries
+}
+
+// refill adds the tokens accumulated since the last refill, up to burst.
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * rate
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+		b.last = now
+	}
+}
+
+// available refills the bucket and reports whether it has a token.
+func (b *tokenBucket) available(now time.Time, rate float64, burst int) bool {
+	b.refill(now, rate, burst)
+	return b.tokens >= 1
+}
+
+// suppressedMessage builds the line reporting n suppressed messages, logged at now.
+func suppressedMessage(n int, key string, keyed bool, now time.Time) EctoLogMessage {
+	fields := []Field{Int64("suppressed", int64(n))}
+	if keyed {
+		fields = append(fields, String("rate_limit_key", key))
+	}
+	return EctoLogMessage{
+		Level:       WarnLevel,
+		Message:     "log messages suppressed by rate limit",
+		Fields:      map[string]interface{}{},
+		TypedFields: fields,
+		Time:        now,
+	}
+}
@@ -0,0 +1,182 @@
+package ectologger
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterGlobalBucket(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	limiter := NewRateLimiter(rec.Log, RateLimiterOptions{Rate: 2, Burst: 2, Clock: clock})
+
+	for i := 0; i < 5; i++ {
+		limiter.Log(EctoLogMessage{Level: InfoLevel, Message: "hit"})
+	}
+	assert.Equal(t, []string{"hit", "hit"}, rec.Messages())
+
+	// Half a second refills one token, taken by the message the summary is logged with
+	clock.Advance(500 * time.Millisecond)
+	limiter.Log(EctoLogMessage{Level: InfoLevel, Message: "after refill"})
+	require.Len(t, rec.messages, 4)
+	assert.Equal(t, "log messages suppressed by rate limit", rec.messages[2].Message)
+	assert.Equal(t, map[string]interface{}{"suppressed": int64(3)}, FieldsToMap(rec.messages[2].TypedFields))
+	assert.Equal(t, "after refill", rec.messages[3].Message)
+	assert.Zero(t, limiter.global.tokens)
+}
+
+func TestRateLimiterSummariesTakeNoToken(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	limiter := NewRateLimiter(rec.Log, RateLimiterOptions{Rate: 1, Burst: 1, Clock: clock})
+
+	limiter.Log(EctoLogMessage{Message: "m0"})
+	limiter.Log(EctoLogMessage{Message: "dropped"})
+	// Traffic at exactly the configured rate is never dropped after a burst
+	for _, m := range []string{"t1", "t2", "t3"} {
+		clock.Advance(time.Second)
+		limiter.Log(EctoLogMessage{Message: m})
+	}
+
+	assert.Equal(t, []string{"m0", "log messages suppressed by rate limit", "t1", "t2", "t3"}, rec.Messages())
+	assert.GreaterOrEqual(t, limiter.global.tokens, 0.0)
+}
+
+func TestRateLimiterGlobalRejectionKeepsKeyToken(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	limiter := NewRateLimiter(rec.Log, RateLimiterOptions{
+		Rate:    1,
+		KeyFunc: func(msg EctoLogMessage) string { return msg.Message },
+		// A key bucket refills far slower than the global one
+		KeyRate:  0.001,
+		KeyBurst: 1,
+		Clock:    clock,
+	})
+
+	limiter.Log(EctoLogMessage{Message: "a"})
+	limiter.Log(EctoLogMessage{Message: "b"}) // Rejected by the global bucket
+	clock.Advance(time.Second)
+	limiter.Log(EctoLogMessage{Message: "b"})
+
+	// The key bucket of b still had its token, so only the global summary is logged
+	assert.Equal(t, []string{"a", "log messages suppressed by rate limit", "b"}, rec.Messages())
+	assert.Equal(t, map[string]interface{}{"suppressed": int64(1)}, FieldsToMap(rec.messages[1].TypedFields))
+	assert.Zero(t, limiter.keys["b"].suppressed)
+}
+
+func TestRateLimiterPerKeyBuckets(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	limiter := NewRateLimiter(rec.Log, RateLimiterOptions{
+		KeyFunc: func(msg EctoLogMessage) string { return msg.Fields["tenant"].(string) },
+		KeyRate: 1,
+		Clock:   clock,
+	})
+
+	tenant := func(name string) EctoLogMessage {
+		return EctoLogMessage{Level: InfoLevel, Message: name, Fields: map[string]interface{}{"tenant": name}}
+	}
+
+	limiter.Log(tenant("a"))
+	limiter.Log(tenant("a"))
+	limiter.Log(tenant("b"))
+	assert.Equal(t, []string{"a", "b"}, rec.Messages())
+
+	limiter.Flush()
+	require.Len(t, rec.messages, 3)
+	assert.Equal(t, map[string]interface{}{"suppressed": int64(1), "rate_limit_key": "a"}, FieldsToMap(rec.messages[2].TypedFields))
+}
+
+func TestRateLimiterMaxKeys(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	limiter := NewRateLimiter(rec.Log, RateLimiterOptions{
+		KeyFunc: func(msg EctoLogMessage) string { return msg.Message },
+		KeyRate: 1,
+		MaxKeys: 2,
+		Clock:   clock,
+	})
+
+	limiter.Log(EctoLogMessage{Message: "a"})
+	limiter.Log(EctoLogMessage{Message: "b"})
+	clock.Advance(time.Second)
+	limiter.Log(EctoLogMessage{Message: "c"})
+
+	assert.Len(t, limiter.keys, 1)
+}
+
+func TestRateLimiterMaxKeysReportsClearedCounts(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	limiter := NewRateLimiter(rec.Log, RateLimiterOptions{
+		KeyFunc: func(msg EctoLogMessage) string { return msg.Message },
+		KeyRate: 1,
+		MaxKeys: 2,
+		Clock:   clock,
+	})
+
+	for _, key := range []string{"a", "a", "b", "b", "c"} {
+		limiter.Log(EctoLogMessage{Message: key})
+	}
+
+	require.Len(t, rec.messages, 5)
+	summaries := map[interface{}]interface{}{}
+	for _, msg := range rec.messages[2:4] {
+		fields := FieldsToMap(msg.TypedFields)
+		summaries[fields["rate_limit_key"]] = fields["suppressed"]
+	}
+	assert.Equal(t, map[interface{}]interface{}{"a": int64(1), "b": int64(1)}, summaries)
+	assert.Equal(t, "c", rec.messages[4].Message)
+}
+
+func TestRateLimiterClearedCountsWaitForAllowedMessage(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	limiter := NewRateLimiter(rec.Log, RateLimiterOptions{
+		Rate:    1,
+		Burst:   2,
+		KeyFunc: func(msg EctoLogMessage) string { return msg.Message },
+		KeyRate: 1,
+		MaxKeys: 2,
+		Clock:   clock,
+	})
+
+	limiter.Log(EctoLogMessage{Message: "a"})
+	limiter.Log(EctoLogMessage{Message: "a"}) // Suppressed by the key bucket of a
+	limiter.Log(EctoLogMessage{Message: "b"}) // Takes the last global token
+	limiter.Log(EctoLogMessage{Message: "c"}) // Clears the buckets of a and b, rejected by the global bucket
+	assert.Equal(t, []string{"a", "b"}, rec.Messages())
+
+	clock.Advance(time.Second)
+	limiter.Log(EctoLogMessage{Message: "d"})
+	require.Len(t, rec.messages, 5)
+	assert.Equal(t, map[string]interface{}{"suppressed": int64(1), "rate_limit_key": "a"}, FieldsToMap(rec.messages[2].TypedFields))
+	assert.Equal(t, map[string]interface{}{"suppressed": int64(1)}, FieldsToMap(rec.messages[3].TypedFields))
+	assert.Equal(t, "d", rec.messages[4].Message)
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	rec := &messageRecorder{}
+	limiter := NewRateLimiter(rec.Log, RateLimiterOptions{Rate: 1, Burst: 50, Clock: newManualClock()})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				limiter.Log(EctoLogMessage{Level: InfoLevel, Message: "hit"})
+			}
+		}()
+	}
+	wg.Wait()
+	limiter.Flush()
+
+	require.Len(t, rec.messages, 51)
+	assert.Equal(t, map[string]interface{}{"suppressed": int64(950)}, FieldsToMap(rec.messages[50].TypedFields))
+}
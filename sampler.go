@@ -0,0 +1,213 @@
+package ectologger
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// SamplerOptions configures a Sampler.
+type SamplerOptions struct {
+	// Interval is the window the per key counters are reset after. Defaults to one second.
+	Interval time.Duration
+	// First is the number of messages per key logged unconditionally in each interval.
+	First int
+	// Thereafter logs every Thereafter-th message per key once First is exceeded.
+	// When zero, messages past First are logged with Probability instead.
+	Thereafter int
+	// Probability is the chance a message past First is logged when Thereafter is zero.
+	// When both are zero every message past First is dropped.
+	Probability float64
+	// KeyFunc groups messages for counting. Defaults to the level and message.
+	KeyFunc func(msg EctoLogMessage) string
+	// NeverSampleErrors logs every message at ErrorLevel and above regardless of the counters.
+	NeverSampleErrors bool
+	// Clock tells the current time. Defaults to SystemClock.
+	Clock Clock
+	// Rand returns a number in [0, 1) for probabilistic sampling. Defaults to rand.Float64.
+	Rand func() float64
+}
+
+// Sampler is a log function wrapper that limits how often identical messages are logged.
+// For each key it logs the first First messages per interval and then only every
+// Thereafter-th (or a random Probability share), emitting a summary line with the number
+// of sampled out messages when the interval ends. The summary is emitted by a timer even
+// when no further message arrives; Close stops the timer and emits the pending summary.
+type Sampler struct {
+	next EctoLogFunc
+	opts SamplerOptions
+
+	mu          sync.Mutex
+	counts      map[string]int // Messages seen per key in the current interval
+	dropped     map[string]int // Messages sampled out per key in the current interval
+	intervalEnd time.Time
+	timer       *time.Timer // Emits the summary once an interval with sampled out messages ends
+	armed       bool        // Whether timer is scheduled
+	closed      bool
+}
+
+// NewSampler returns a Sampler that passes the messages it keeps to next.
+func NewSampler(next EctoLogFunc, opts SamplerOptions) *Sampler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = DefaultSampleKey
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.Float64
+	}
+	return &Sampler{next: next, opts: opts, counts: map[string]int{}, dropped: map[string]int{}}
+}
+
+// DefaultSampleKey groups messages by level and message text.
+func DefaultSampleKey(msg EctoLogMessage) string {
+	return msg.Level + "\x00" + msg.Message
+}
+
+// Log logs msg through the wrapped log function unless it is sampled out.
+// It has the signature of an EctoLogFunc so s.Log can be passed to NewEctoLogger.
+func (s *Sampler) Log(msg EctoLogMessage) {
+	if s.opts.NeverSampleErrors && LevelEnabled(msg.Level, ErrorLevel) {
+		s.next(msg)
+		return
+	}
+
+	key := s.opts.KeyFunc(msg)
+	now := s.opts.Clock.Now()
+
+	s.mu.Lock()
+	summary, hasSummary := s.rollover(now)
+	s.counts[key]++
+	keep := s.keep(s.counts[key])
+	if !keep {
+		s.dropped[key]++
+		s.arm(now)
+	}
+	s.mu.Unlock()
+
+	// Log outside the lock so a slow sink does not serialize unrelated keys
+	if hasSummary {
+		s.next(summary)
+	}
+	if keep {
+		s.next(msg)
+	}
+}
+
+// Flush emits the summary of the current interval, if anything was sampled out, and starts a new interval.
+func (s *Sampler) Flush() {
+	now := s.opts.Clock.Now()
+	s.mu.Lock()
+	summary, hasSummary := s.summary(now)
+	s.reset(now)
+	s.mu.Unlock()
+
+	if hasSummary {
+		s.next(summary)
+	}
+}
+
+// Close stops emitting summaries from the timer and emits the summary of the current
+// interval. Messages logged after Close are still sampled, but their summary is only
+// emitted when a later message ends the interval or Flush is called.
+func (s *Sampler) Close() {
+	s.mu.Lock()
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+		s.armed = false
+	}
+	s.mu.Unlock()
+
+	s.Flush()
+}
+
+// arm schedules the timer for the end of the current interval, unless it already is or the
+// sampler is closed. s.mu must be held.
+func (s *Sampler) arm(now time.Time) {
+	if s.armed || s.closed {
+		return
+	}
+	s.armed = true
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.intervalEnd.Sub(now), s.flushEnded)
+	} else {
+		s.timer.Reset(s.intervalEnd.Sub(now))
+	}
+}
+
+// flushEnded runs on the timer and emits the summary of the current interval if it has ended.
+// Otherwise, e.g. when the Clock runs behind the timer, it is scheduled again.
+func (s *Sampler) flushEnded() {
+	now := s.opts.Clock.Now()
+	s.mu.Lock()
+	s.armed = false
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	summary, hasSummary := s.rollover(now)
+	if len(s.dropped) > 0 {
+		s.arm(now)
+	}
+	s.mu.Unlock()
+
+	if hasSummary {
+		s.next(summary)
+	}
+}
+
+// keep reports whether the n-th message of a key in the current interval is logged.
+func (s *Sampler) keep(n int) bool {
+	if n <= s.opts.First {
+		return true
+	}
+	if s.opts.Thereafter > 0 {
+		return (n-s.opts.First)%s.opts.Thereafter == 0
+	}
+	return s.opts.Probability > 0 && s.opts.Rand() < s.opts.Probability
+}
+
+// rollover starts a new interval when the current one has ended, returning the summary of the ended interval.
+// s.mu must be held.
+func (s *Sampler) rollover(now time.Time) (EctoLogMessage, bool) {
+	if now.Before(s.intervalEnd) {
+		return EctoLogMessage{}, false
+	}
+	summary, hasSummary := s.summary(now)
+	s.reset(now)
+	return summary, hasSummary
+}
+
+// summary builds the summary line of the current interval, logged at now. s.mu must be held.
+func (s *Sampler) summary(now time.Time) (EctoLogMessage, bool) {
+	if len(s.dropped) == 0 {
+		return EctoLogMessage{}, false
+	}
+	total := 0
+	for _, n := range s.dropped {
+		total += n
+	}
+	return EctoLogMessage{
+		Level:   InfoLevel,
+		Message: "sampled out log messages",
+		Fields:  map[string]interface{}{},
+		Time:    now,
+		TypedFields: []Field{
+			Int64("sampled_out", int64(total)),
+			Int64("sampled_keys", int64(len(s.dropped))),
+			Duration("sample_interval", s.opts.Interval),
+		},
+	}, true
+}
+
+// reset starts a new interval at now. s.mu must be held.
+func (s *Sampler) reset(now time.Time) {
+	s.counts = map[string]int{}
+	s.dropped = map[string]int{}
+	s.intervalEnd = now.Add(s.opts.Interval)
+}
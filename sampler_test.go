@@ -0,0 +1,174 @@
+package ectologger
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manualClock is a clock for tests that only moves when advanced.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newManualClock() *manualClock {
+	return &manualClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// messageRecorder collects the messages passed to its Log method.
+type messageRecorder struct {
+	mu       sync.Mutex
+	messages []EctoLogMessage
+}
+
+func (r *messageRecorder) Log(msg EctoLogMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+}
+
+func (r *messageRecorder) Messages() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]string, 0, len(r.messages))
+	for _, msg := range r.messages {
+		result = append(result, msg.Message)
+	}
+	return result
+}
+
+func TestSamplerFirstThenEveryNth(t *testing.T) {
+	clock := newManualClock()
+	rec := &messageRecorder{}
+	sampler := NewSampler(rec.Log, SamplerOptions{Interval: time.Second, First: 2, Thereafter: 3, Clock: clock})
+
+	for i := 0; i < 8; i++ {
+		sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "hit"})
+	}
+
+	// 1 and 2 pass as First, then 5 and 8 as every third
+	assert.Len(t, rec.Messages(), 4)
+
+	clock.Advance(time.Second)
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "hit"})
+
+	require.Len(t, rec.messages, 6)
+	summary := rec.messages[4]
+	assert.Equal(t, "sampled out log messages", summary.Message)
+	assert.Equal(t, map[string]interface{}{
+		"sampled_out":     int64(4),
+		"sampled_keys":    int64(1),
+		"sample_interval": time.Second,
+	}, FieldsToMap(summary.TypedFields))
+	assert.Equal(t, "hit", rec.messages[5].Message)
+}
+
+func TestSamplerKeysAreIndependent(t *testing.T) {
+	rec := &messageRecorder{}
+	sampler := NewSampler(rec.Log, SamplerOptions{First: 1, Clock: newManualClock()})
+
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "a"})
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "a"})
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "b"})
+	sampler.Log(EctoLogMessage{Level: WarnLevel, Message: "a"})
+
+	assert.Equal(t, []string{"a", "b", "a"}, rec.Messages())
+}
+
+func TestSamplerNeverSampleErrors(t *testing.T) {
+	rec := &messageRecorder{}
+	sampler := NewSampler(rec.Log, SamplerOptions{First: 1, NeverSampleErrors: true, Clock: newManualClock()})
+
+	for i := 0; i < 3; i++ {
+		sampler.Log(EctoLogMessage{Level: ErrorLevel, Message: "error"})
+		sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "info"})
+	}
+
+	assert.Equal(t, []string{"error", "info", "error", "error"}, rec.Messages())
+}
+
+func TestSamplerProbabilityAndKeyFunc(t *testing.T) {
+	rec := &messageRecorder{}
+	rolls := []float64{0.1, 0.9}
+	sampler := NewSampler(rec.Log, SamplerOptions{
+		Probability: 0.5,
+		KeyFunc:     func(msg EctoLogMessage) string { return "all" },
+		Clock:       newManualClock(),
+		Rand: func() float64 {
+			r := rolls[0]
+			rolls = rolls[1:]
+			return r
+		},
+	})
+
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "kept"})
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "dropped"})
+	sampler.Flush()
+
+	assert.Equal(t, []string{"kept", "sampled out log messages"}, rec.Messages())
+}
+
+func TestSamplerConcurrent(t *testing.T) {
+	rec := &messageRecorder{}
+	sampler := NewSampler(rec.Log, SamplerOptions{First: 10, Clock: newManualClock()})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "hit"})
+			}
+		}()
+	}
+	wg.Wait()
+	sampler.Flush()
+
+	assert.Len(t, rec.Messages(), 11)
+}
+
+func TestSamplerEmitsSummaryWithoutFurtherMessages(t *testing.T) {
+	rec := &messageRecorder{}
+	sampler := NewSampler(rec.Log, SamplerOptions{Interval: 20 * time.Millisecond, First: 1})
+	defer sampler.Close()
+
+	for i := 0; i < 3; i++ {
+		sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "repeated"})
+	}
+
+	assert.Eventually(t, func() bool {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		return len(rec.messages) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"repeated", "sampled out log messages"}, rec.Messages())
+}
+
+func TestSamplerClose(t *testing.T) {
+	rec := &messageRecorder{}
+	sampler := NewSampler(rec.Log, SamplerOptions{Interval: time.Hour, First: 1})
+
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "repeated"})
+	sampler.Log(EctoLogMessage{Level: InfoLevel, Message: "repeated"})
+	sampler.Close()
+
+	assert.Equal(t, []string{"repeated", "sampled out log messages"}, rec.Messages())
+	assert.False(t, sampler.armed)
+}
@@ -0,0 +1,118 @@
+package ectologger
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// stdOutput is the logger DefaultEctoLogFunc and LogfmtEctoLogFunc write to while the
+// global log package is redirected, so their output does not loop back into a Logger.
+var stdOutput atomic.Pointer[log.Logger]
+
+// stdLogger returns the logger the built-in encoders write to.
+func stdLogger() *log.Logger {
+	if l := stdOutput.Load(); l != nil {
+		return l
+	}
+	return log.Default()
+}
+
+// Writer is an io.Writer that logs every line written to it as a message at a fixed level.
+// Partial lines are buffered until their newline arrives or the writer is flushed.
+type Writer struct {
+	logger Logger
+	level  string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+var _ io.WriteCloser = (*Writer)(nil)
+
+// NewWriter returns a Writer logging through logger at level.
+func NewWriter(logger Logger, level string) *Writer {
+	return &Writer{logger: logger, level: level}
+}
+
+// Write logs each complete line in p and buffers a trailing partial line.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	w.buf = append(w.buf, p...)
+	var lines [][]byte
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		lines = append(lines, w.buf[:idx])
+		w.buf = w.buf[idx+1:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	w.mu.Unlock()
+
+	for _, line := range lines {
+		w.logLine(line)
+	}
+	return len(p), nil
+}
+
+// Flush logs the buffered partial line, if any.
+func (w *Writer) Flush() {
+	w.mu.Lock()
+	line := w.buf
+	w.buf = nil
+	w.mu.Unlock()
+
+	w.logLine(line)
+}
+
+// Close flushes the writer. It never returns an error.
+func (w *Writer) Close() error {
+	w.Flush()
+	return nil
+}
+
+// logLine logs a single line, skipping empty ones.
+func (w *Writer) logLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) == 0 {
+		return
+	}
+	LogAt(w.logger, w.level, string(line))
+}
+
+// NewStdLogger returns a *log.Logger whose output is logged through logger at level, one
+// message per line. It can be passed to third-party code such as net/http.Server.ErrorLog.
+func NewStdLogger(logger Logger, level string) *log.Logger {
+	return log.New(NewWriter(logger, level), "", 0)
+}
+
+// RedirectStdLog captures the output of the global log package and logs it through logger
+// at level. DefaultEctoLogFunc and LogfmtEctoLogFunc keep writing to the previous output
+// of the log package, so a logger using them does not feed its own output back into itself.
+// The returned function undoes the redirect.
+func RedirectStdLog(logger Logger, level string) (restore func()) {
+	prevWriter, prevFlags, prevPrefix := log.Writer(), log.Flags(), log.Prefix()
+	prevOutput := stdOutput.Load()
+
+	if prevOutput == nil {
+		stdOutput.Store(log.New(prevWriter, prevPrefix, prevFlags))
+	}
+	writer := NewWriter(logger, level)
+	log.SetOutput(writer)
+	log.SetFlags(0)
+	log.SetPrefix("")
+
+	return func() {
+		writer.Flush()
+		log.SetOutput(prevWriter)
+		log.SetFlags(prevFlags)
+		log.SetPrefix(prevPrefix)
+		stdOutput.Store(prevOutput)
+	}
+}
@@ -0,0 +1,59 @@
+package ectologger
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterBuffersPartialLines(t *testing.T) {
+	rec := &messageRecorder{}
+	w := NewWriter(NewEctoLogger(rec.Log), WarnLevel)
+
+	_, err := w.Write([]byte("first li"))
+	require.NoError(t, err)
+	assert.Empty(t, rec.Messages())
+
+	_, err = w.Write([]byte("ne\r\nsecond line\n\nthird"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first line", "second line"}, rec.Messages())
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{"first line", "second line", "third"}, rec.Messages())
+	assert.Equal(t, WarnLevel, rec.messages[0].Level)
+}
+
+func TestNewStdLogger(t *testing.T) {
+	rec := &messageRecorder{}
+	std := NewStdLogger(NewEctoLogger(rec.Log), ErrorLevel)
+
+	std.Printf("http: TLS handshake error from %s", "127.0.0.1")
+
+	require.Len(t, rec.messages, 1)
+	assert.Equal(t, ErrorLevel, rec.messages[0].Level)
+	assert.Equal(t, "http: TLS handshake error from 127.0.0.1", rec.messages[0].Message)
+}
+
+func TestRedirectStdLogAvoidsRecursion(t *testing.T) {
+	var out bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&out)
+	defer log.SetOutput(prev)
+
+	restore := RedirectStdLog(NewDefaultEctoLogger(), InfoLevel)
+	log.Print("from the log package")
+	restore()
+
+	// The redirected line is encoded once by DefaultEctoLogFunc and written to the previous output
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"message":"from the log package"`)
+
+	log.Print("after restore")
+	assert.Contains(t, out.String(), "after restore")
+	assert.Nil(t, stdOutput.Load())
+}
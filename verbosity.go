@@ -0,0 +1,187 @@
+package ectologger
+
+import (
+	"flag"
+	"fmt"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Verbosity decides which V levels are logged, as glog's -v and -vmodule flags do. A call to
+// Logger.V(n) logs when n is at most the global level, or at most the level of the first module
+// rule whose pattern matches the source file of the call. Module rules can only raise the
+// verbosity of their files. The zero value logs only V(0) and has no module rules.
+//
+// The module level is resolved once per call site and cached by program counter, so a
+// disabled V call costs a few atomic loads and no allocations.
+type Verbosity struct {
+	level      atomic.Int32
+	hasModules atomic.Bool
+
+	mu      sync.Mutex // Serializes changes to modules and sites
+	modules []moduleRule
+	sites   atomic.Pointer[map[uintptr]int] // Module level per call site, -1 if no rule matches
+}
+
+// moduleRule is the V level of the source files matching a pattern.
+type moduleRule struct {
+	pattern string
+	level   int
+}
+
+// NewVerbosity returns a Verbosity with the given global level and the module rules of vmodule,
+// see SetModules.
+func NewVerbosity(level int, vmodule string) (*Verbosity, error) {
+	v := &Verbosity{}
+	v.SetLevel(level)
+	if err := v.SetModules(vmodule); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// WithVerbosity sets the Verbosity deciding which V levels the logger and its sub loggers log.
+// Without it only V(0) is logged.
+func WithVerbosity(verbosity *Verbosity) Option {
+	return func(l *EctoLogger) {
+		l.verbosity = verbosity
+	}
+}
+
+// SetLevel sets the global V level.
+func (v *Verbosity) SetLevel(level int) {
+	v.level.Store(int32(level))
+}
+
+// Level returns the global V level.
+func (v *Verbosity) Level() int {
+	return int(v.level.Load())
+}
+
+// SetModules replaces the module rules with those of spec, a comma separated list of
+// pattern=level pairs such as "handler*=3,db/pool=2". A pattern without a slash is matched
+// against the base name of the source file without its .go extension; a pattern with slashes
+// is matched against as many trailing path elements. Patterns use the syntax of path.Match.
+// The rules are left unchanged if spec is invalid.
+func (v *Verbosity) SetModules(spec string) error {
+	var modules []moduleRule
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		pattern, value, ok := strings.Cut(rule, "=")
+		pattern = strings.TrimSuffix(strings.TrimSpace(pattern), ".go")
+		if !ok || pattern == "" {
+			return fmt.Errorf("ectologger: invalid vmodule rule %q, expected pattern=level", rule)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("ectologger: invalid vmodule rule %q: %w", rule, err)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("ectologger: invalid vmodule rule %q: level is not a number", rule)
+		}
+		modules = append(modules, moduleRule{pattern: pattern, level: level})
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.modules = modules
+	v.sites.Store(&map[uintptr]int{})
+	v.hasModules.Store(len(modules) > 0)
+	return nil
+}
+
+// Modules returns the module rules in the format accepted by SetModules.
+func (v *Verbosity) Modules() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	rules := make([]string, len(v.modules))
+	for i, m := range v.modules {
+		rules[i] = m.pattern + "=" + strconv.Itoa(m.level)
+	}
+	return strings.Join(rules, ",")
+}
+
+// RegisterFlags defines the -v and -vmodule flags setting the global level and the module rules.
+func (v *Verbosity) RegisterFlags(fs *flag.FlagSet) {
+	fs.Func("v", "log level for V logs", func(s string) error {
+		level, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetLevel(level)
+		return nil
+	})
+	fs.Func("vmodule", "comma separated list of pattern=N settings for file-filtered V logs", v.SetModules)
+}
+
+// enabled reports whether V(level) is logged at the call site of the caller of its caller.
+// A nil Verbosity logs only V(0) and below.
+func (v *Verbosity) enabled(level int) bool {
+	if v == nil {
+		return level <= 0
+	}
+	if level <= v.Level() {
+		return true
+	}
+	if !v.hasModules.Load() {
+		return false
+	}
+
+	var pcs [1]uintptr
+	// Skip runtime.Callers, enabled and the V method
+	if runtime.Callers(3, pcs[:]) == 0 {
+		return false
+	}
+	if sites := v.sites.Load(); sites != nil {
+		if moduleLevel, ok := (*sites)[pcs[0]]; ok {
+			return level <= moduleLevel
+		}
+	}
+	return level <= v.resolveSite(pcs[0])
+}
+
+// resolveSite returns the module level of the call site at pc, or -1 if no rule matches its
+// file, and caches it.
+func (v *Verbosity) resolveSite(pc uintptr) int {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	file := strings.TrimSuffix(frame.File, ".go")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	moduleLevel := -1
+	for _, m := range v.modules {
+		if matchModule(m.pattern, file) {
+			moduleLevel = m.level
+			break
+		}
+	}
+
+	// Copy on write so lookups never take the lock
+	sites := map[uintptr]int{}
+	if old := v.sites.Load(); old != nil {
+		for k, l := range *old {
+			sites[k] = l
+		}
+	}
+	sites[pc] = moduleLevel
+	v.sites.Store(&sites)
+	return moduleLevel
+}
+
+// matchModule reports whether pattern matches the trailing path elements of file.
+func matchModule(pattern, file string) bool {
+	elems := strings.Split(file, "/")
+	n := strings.Count(pattern, "/") + 1
+	if n > len(elems) {
+		return false
+	}
+	matched, _ := path.Match(pattern, strings.Join(elems[len(elems)-n:], "/"))
+	return matched
+}
@@ -0,0 +1,118 @@
+package ectologger
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logV logs at verbosity level n from a single call site.
+func logV(logger Logger, n int) {
+	logger.V(n).Info("test message")
+}
+
+func TestVWithoutVerbosity(t *testing.T) {
+	rec := &messageRecorder{}
+	logger := NewEctoLogger(rec.Log)
+
+	logV(logger, 0)
+	logV(logger, 1)
+	logV(logger.WithField("key", "value"), 1)
+
+	assert.Len(t, rec.messages, 1)
+}
+
+func TestVGlobalLevel(t *testing.T) {
+	verbosity, err := NewVerbosity(2, "")
+	require.NoError(t, err)
+	rec := &messageRecorder{}
+	logger := NewEctoLogger(rec.Log, WithVerbosity(verbosity))
+
+	logger.V(2).WithField("key", "value").Info("logged")
+	logger.WithField("key", "value").V(3).Info("dropped")
+	verbosity.SetLevel(3)
+	logger.WithField("key", "value").V(3).Debug("logged after SetLevel")
+
+	assert.Equal(t, []string{"logged", "logged after SetLevel"}, rec.Messages())
+	assert.Equal(t, map[string]interface{}{"key": "value"}, rec.messages[1].FieldMap())
+	assert.Equal(t, DebugLevel, rec.messages[1].Level)
+}
+
+func TestVModuleOverridesByFile(t *testing.T) {
+	verbosity, err := NewVerbosity(1, "other=5, verbosity_t*=3")
+	require.NoError(t, err)
+	rec := &messageRecorder{}
+	logger := NewEctoLogger(rec.Log, WithVerbosity(verbosity))
+
+	logV(logger, 3)
+	logV(logger, 4)
+	assert.Len(t, rec.messages, 1)
+
+	// The cached decision of the call site is dropped when the rules change
+	require.NoError(t, verbosity.SetModules("*/verbosity_test=4"))
+	logV(logger, 4)
+	assert.Len(t, rec.messages, 2)
+
+	require.NoError(t, verbosity.SetModules("other=5"))
+	logV(logger, 4)
+	logV(logger, 1)
+	assert.Len(t, rec.messages, 3)
+}
+
+func TestVModuleOnlyRaisesVerbosity(t *testing.T) {
+	verbosity, err := NewVerbosity(3, "verbosity_test=1")
+	require.NoError(t, err)
+	rec := &messageRecorder{}
+
+	logV(NewEctoLogger(rec.Log, WithVerbosity(verbosity)), 3)
+
+	assert.Len(t, rec.messages, 1)
+}
+
+func TestVDisabledDoesNotAllocate(t *testing.T) {
+	verbosity, err := NewVerbosity(0, "other=5")
+	require.NoError(t, err)
+	logger := NewEctoLogger(func(EctoLogMessage) {}, WithVerbosity(verbosity)).WithField("key", "value")
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		logger.V(2).Info("test message")
+	}))
+}
+
+func TestVerbosityRegisterFlags(t *testing.T) {
+	verbosity := &Verbosity{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	verbosity.RegisterFlags(fs)
+
+	require.NoError(t, fs.Parse([]string{"-v=2", "-vmodule=handler*=3,db/pool.go=4"}))
+
+	assert.Equal(t, 2, verbosity.Level())
+	assert.Equal(t, "handler*=3,db/pool=4", verbosity.Modules())
+	assert.Error(t, fs.Parse([]string{"-v=high"}))
+}
+
+func TestVerbosityRejectsInvalidModules(t *testing.T) {
+	verbosity, err := NewVerbosity(0, "db=2")
+	require.NoError(t, err)
+
+	assert.EqualError(t, verbosity.SetModules("handler"), `ectologger: invalid vmodule rule "handler", expected pattern=level`)
+	assert.EqualError(t, verbosity.SetModules("handler=high"), `ectologger: invalid vmodule rule "handler=high": level is not a number`)
+	assert.ErrorContains(t, verbosity.SetModules("[=2"), "syntax error in pattern")
+	assert.Equal(t, "db=2", verbosity.Modules())
+}
+
+func TestMatchModule(t *testing.T) {
+	file := "/src/app/internal/db/pool"
+
+	assert.True(t, matchModule("pool", file))
+	assert.True(t, matchModule("po*", file))
+	assert.True(t, matchModule("db/pool", file))
+	assert.True(t, matchModule("internal/*/pool", file))
+	assert.False(t, matchModule("ool", file))
+	assert.False(t, matchModule("app/pool", file))
+	assert.False(t, matchModule("a/b/c/d/e/f/pool", file))
+}
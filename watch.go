@@ -0,0 +1,279 @@
+package ectologger
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatcherOptions configures a ConfigWatcher.
+type WatcherOptions struct {
+	// Interval is how often the file is polled for changes. Defaults to one second.
+	Interval time.Duration
+	// ApplyEnv applies the ECTOLOG_* environment variables to every configuration read,
+	// see Config.ApplyEnv.
+	ApplyEnv bool
+	// Logger receives the watcher's own messages: the changes made by each reload and the
+	// reloads that failed. Defaults to a logger writing through the watcher.
+	Logger Logger
+}
+
+// ConfigWatcher is a log function that writes through the pipeline described by a config
+// file, as NewFromConfig does, and rebuilds it when the file changes. The file is polled:
+// it is re-read when its modification time or size changes and reloaded when its contents
+// hash differently. An invalid config is logged and the previous pipeline is kept.
+//
+// A reload swaps pipelines while no message is being written, so messages logged through
+// the watcher during a reload go entirely to either the old or the new pipeline.
+type ConfigWatcher struct {
+	path   string
+	opts   WatcherOptions
+	logger Logger
+
+	mu       sync.RWMutex // Held for writing while pipelines are swapped
+	pipeline *pipeline
+	cfg      Config
+
+	reloadMu sync.Mutex // Serializes reloads
+	modTime  time.Time
+	size     int64
+	hash     [sha256.Size]byte
+	lastErr  string
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// WatchConfigFile loads the config file at path and returns a ConfigWatcher polling it for
+// changes. An invalid initial config is returned as an error. Close stops the watcher.
+func WatchConfigFile(path string, opts WatcherOptions) (*ConfigWatcher, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	w := &ConfigWatcher{path: path, opts: opts, stop: make(chan struct{}), done: make(chan struct{})}
+	w.logger = opts.Logger
+	if w.logger == nil {
+		w.logger = NewEctoLogger(w.Log)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, p, err := w.build(data)
+	if err != nil {
+		return nil, err
+	}
+	w.pipeline, w.cfg = p, cfg
+	w.modTime, w.size, w.hash = info.ModTime(), info.Size(), sha256.Sum256(data)
+
+	go w.poll()
+	return w, nil
+}
+
+// Log logs msg through the current pipeline.
+// It has the signature of an EctoLogFunc so w.Log can be passed to NewEctoLogger.
+func (w *ConfigWatcher) Log(msg EctoLogMessage) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.pipeline != nil {
+		w.pipeline.logFunc(msg)
+	}
+}
+
+// Config returns the configuration currently in use.
+func (w *ConfigWatcher) Config() Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.cfg
+}
+
+// Reload re-reads the config file regardless of its modification time and applies it if its
+// contents changed. It returns the error that kept an invalid config from being applied.
+func (w *ConfigWatcher) Reload() error {
+	return w.reload(true)
+}
+
+// Close stops polling, flushes the current pipeline and closes its files. Messages logged
+// after Close are discarded.
+func (w *ConfigWatcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		err = w.pipeline.close()
+		w.pipeline = nil
+	})
+	return err
+}
+
+// poll checks the config file every interval until the watcher is closed.
+func (w *ConfigWatcher) poll() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			_ = w.reload(false)
+		}
+	}
+}
+
+// reload applies the config file if it changed. Unless force is set the file is only read
+// when its modification time or size differs from the last read.
+func (w *ConfigWatcher) reload(force bool) error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return w.fail(err)
+	}
+	if !force && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return nil
+	}
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return w.fail(err)
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	hash := sha256.Sum256(data)
+	if hash == w.hash && !(force && w.lastErr != "") {
+		return nil
+	}
+	// Remember the contents even if they are invalid so they are only reported once
+	w.hash = hash
+
+	cfg, p, err := w.build(data)
+	if err != nil {
+		return w.fail(err)
+	}
+	w.lastErr = ""
+
+	w.mu.Lock()
+	old, oldCfg := w.pipeline, w.cfg
+	if old == nil {
+		// Closed during the reload
+		w.mu.Unlock()
+		return p.close()
+	}
+	w.pipeline, w.cfg = p, cfg
+	// Flush the old pipeline before releasing the lock so its sampler summaries are
+	// written ahead of messages logged through the new one
+	closeErr := old.close()
+	w.mu.Unlock()
+
+	if closeErr != nil {
+		reportError(fmt.Errorf("ectologger: closing previous logging config: %w", closeErr))
+	}
+	w.logger.WithTypedFields(
+		String("config.path", w.path),
+		Array("config.changes", toInterfaces(diffConfig(oldCfg, cfg))...),
+	).Info("logging config reloaded")
+	return nil
+}
+
+// build parses the config file contents and builds their pipeline.
+func (w *ConfigWatcher) build(data []byte) (Config, *pipeline, error) {
+	cfg, err := parseConfigFile(w.path, data)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	if w.opts.ApplyEnv {
+		if err := cfg.ApplyEnv(); err != nil {
+			return Config{}, nil, err
+		}
+	}
+	p, err := buildPipeline(cfg)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	return cfg, p, nil
+}
+
+// fail logs a failed reload, unless the same error was logged by the previous one, and returns err.
+func (w *ConfigWatcher) fail(err error) error {
+	if msg := err.Error(); msg != w.lastErr {
+		w.lastErr = msg
+		w.logger.WithError(err).WithTypedFields(String("config.path", w.path)).
+			Error("logging config reload failed, keeping the previous config")
+	}
+	return err
+}
+
+// diffConfig describes the settings that differ between two configurations, one
+// "key: old -> new" entry per setting.
+func diffConfig(old, cfg Config) []string {
+	var changes []string
+	add := func(key, from, to string) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", key, from, to))
+		}
+	}
+	add("level", orDefault(old.Level, InfoLevel), orDefault(cfg.Level, InfoLevel))
+	add("format", orDefault(old.Format, FormatJSON), orDefault(cfg.Format, FormatJSON))
+	add("sinks", describeSinks(old.Sinks), describeSinks(cfg.Sinks))
+	add("sampling", describeSampling(old.Sampling), describeSampling(cfg.Sampling))
+	add("redact", fmt.Sprint(old.Redact), fmt.Sprint(cfg.Redact))
+	add("fields", fmt.Sprint(old.Fields), fmt.Sprint(cfg.Fields))
+	return changes
+}
+
+// orDefault returns value, or def when value is empty.
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// describeSinks formats sinks as a list such as [stdout file:/var/log/app.log(json,error)].
+func describeSinks(sinks []SinkConfig) string {
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{Type: SinkStdout}}
+	}
+	parts := make([]string, len(sinks))
+	for i, sc := range sinks {
+		parts[i] = sc.Type
+		if sc.Type == SinkFile {
+			parts[i] += ":" + sc.Path
+		}
+		if sc.Format != "" || sc.Level != "" {
+			parts[i] += "(" + orDefault(sc.Format, "-") + "," + orDefault(sc.Level, "-") + ")"
+		}
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// describeSampling formats the sampling settings, or "off" when there are none.
+func describeSampling(s *SamplingConfig) string {
+	if s == nil {
+		return "off"
+	}
+	return fmt.Sprintf("%+v", *s)
+}
+
+// toInterfaces converts strings to values for Array.
+func toInterfaces(values []string) []interface{} {
+	converted := make([]interface{}, len(values))
+	for i, v := range values {
+		converted[i] = v
+	}
+	return converted
+}
@@ -0,0 +1,160 @@
+package ectologger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestConfigWatcherReloadSwapsPipeline(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "logging.yaml")
+	oldPath := filepath.Join(dir, "old.log")
+	newPath := filepath.Join(dir, "new.log")
+	writeConfig(t, cfgPath, fmt.Sprintf("level: info\nformat: logfmt\nsinks:\n  - type: file\n    path: %s\n", oldPath))
+
+	rec := &messageRecorder{}
+	w, err := WatchConfigFile(cfgPath, WatcherOptions{Interval: time.Hour, Logger: NewEctoLogger(rec.Log)})
+	require.NoError(t, err)
+	defer w.Close()
+	logger := NewEctoLogger(w.Log, WithClock(newManualClock()))
+
+	logger.Debug("dropped")
+	logger.WithField("password", "secret").Info("before")
+
+	writeConfig(t, cfgPath, fmt.Sprintf("level: debug\nformat: logfmt\nsinks:\n  - type: file\n    path: %s\nredact: [password]\n", newPath))
+	require.NoError(t, w.Reload())
+
+	logger.WithField("password", "secret").Debug("after")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"time=2024-01-01T00:00:00Z level=info message=before password=secret"}, readLines(t, oldPath))
+	assert.Equal(t, []string{"time=2024-01-01T00:00:00Z level=debug message=after password=[REDACTED]"}, readLines(t, newPath))
+	assert.Equal(t, DebugLevel, w.Config().Level)
+
+	require.Len(t, rec.messages, 1)
+	assert.Equal(t, "logging config reloaded", rec.messages[0].Message)
+	assert.Equal(t, []interface{}{
+		"level: info -> debug",
+		fmt.Sprintf("sinks: [file:%s] -> [file:%s]", oldPath, newPath),
+		"redact: [] -> [password]",
+	}, rec.messages[0].FieldMap()["config.changes"])
+}
+
+func TestConfigWatcherKeepsPreviousConfigWhenInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "logging.json")
+	logPath := filepath.Join(dir, "app.log")
+	writeConfig(t, cfgPath, fmt.Sprintf(`{"format": "logfmt", "sinks": [{"type": "file", "path": %q}]}`, logPath))
+
+	rec := &messageRecorder{}
+	w, err := WatchConfigFile(cfgPath, WatcherOptions{Interval: time.Hour, Logger: NewEctoLogger(rec.Log)})
+	require.NoError(t, err)
+	defer w.Close()
+	logger := NewEctoLogger(w.Log, WithClock(newManualClock()))
+
+	writeConfig(t, cfgPath, `{"level": "verbose"}`)
+	assert.ErrorIs(t, w.Reload(), ErrInvalidConfig)
+	assert.ErrorIs(t, w.Reload(), ErrInvalidConfig)
+
+	logger.Info("kept")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{"time=2024-01-01T00:00:00Z level=info message=kept"}, readLines(t, logPath))
+	assert.Equal(t, []string{"logging config reload failed, keeping the previous config"}, rec.Messages())
+	assert.ErrorContains(t, rec.messages[0].Err, `level: unknown level "verbose"`)
+}
+
+func TestConfigWatcherPollsForChanges(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "logging.yaml")
+	writeConfig(t, cfgPath, "level: info\nsinks: [{type: stderr}]\n")
+
+	w, err := WatchConfigFile(cfgPath, WatcherOptions{Interval: 5 * time.Millisecond, Logger: NewEctoLogger(func(EctoLogMessage) {})})
+	require.NoError(t, err)
+	defer w.Close()
+
+	writeConfig(t, cfgPath, "level: error\nsinks: [{type: stderr}]\n")
+	assert.Eventually(t, func() bool { return w.Config().Level == ErrorLevel }, time.Second, 5*time.Millisecond)
+}
+
+func TestWatchConfigFileRejectsInvalidConfig(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "logging.yaml")
+
+	_, err := WatchConfigFile(cfgPath, WatcherOptions{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	writeConfig(t, cfgPath, "format: xml\n")
+	_, err = WatchConfigFile(cfgPath, WatcherOptions{})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfigWatcherReloadNeitherDropsNorDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "logging.yaml")
+	paths := []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")}
+	config := func(i int) string {
+		return fmt.Sprintf("sinks:\n  - type: file\n    path: %s\nfields:\n  generation: %d\n", paths[i%2], i)
+	}
+	writeConfig(t, cfgPath, config(0))
+
+	w, err := WatchConfigFile(cfgPath, WatcherOptions{Interval: time.Hour, Logger: NewEctoLogger(func(EctoLogMessage) {})})
+	require.NoError(t, err)
+	logger := NewEctoLogger(w.Log)
+
+	const writers, perWriter = 4, 200
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				logger.Info("message")
+			}
+		}()
+	}
+	for i := 1; i <= 20; i++ {
+		writeConfig(t, cfgPath, config(i))
+		require.NoError(t, w.Reload())
+	}
+	wg.Wait()
+	require.NoError(t, w.Close())
+
+	assert.Len(t, append(readLines(t, paths[0]), readLines(t, paths[1])...), writers*perWriter)
+}
+
+func TestDiffConfig(t *testing.T) {
+	assert.Empty(t, diffConfig(Config{}, Config{Level: InfoLevel, Format: FormatJSON, Sinks: []SinkConfig{{Type: SinkStdout}}}))
+	assert.Equal(t, []string{
+		"format: json -> logfmt",
+		"sinks: [stdout] -> [stderr file:/tmp/app.log(json,error)]",
+		"sampling: off -> {Interval:1s First:10 Thereafter:0 Probability:0 NeverSampleErrors:false}",
+		"fields: map[] -> map[service:billing]",
+	}, diffConfig(Config{}, Config{
+		Format:   FormatLogfmt,
+		Sinks:    []SinkConfig{{Type: SinkStderr}, {Type: SinkFile, Path: "/tmp/app.log", Format: FormatJSON, Level: ErrorLevel}},
+		Sampling: &SamplingConfig{Interval: ConfigDuration(time.Second), First: 10},
+		Fields:   map[string]interface{}{"service": "billing"},
+	}))
+}
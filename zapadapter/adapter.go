@@ -1,6 +1,8 @@
 package zapadapter
 
 import (
+	"time"
+
 	"github.com/Gobusters/ectologger"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -15,32 +17,145 @@ func fieldsToZapFields(fields map[string]interface{}) []zap.Field {
 	return zapFields
 }
 
+// Helper function to convert typed ectologger fields to []zap.Field without reflection
+func typedFieldsToZapFields(fields []ectologger.Field) []zap.Field {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, f := range fields {
+		zapFields = append(zapFields, toZapField(f))
+	}
+	return zapFields
+}
+
+// toZapField maps a typed ectologger field to the equivalent zap field
+func toZapField(f ectologger.Field) zap.Field {
+	switch f.Type {
+	case ectologger.StringType:
+		return zap.String(f.Key, f.String)
+	case ectologger.Int64Type:
+		return zap.Int64(f.Key, f.Integer)
+	case ectologger.Float64Type:
+		return zap.Float64(f.Key, f.Float)
+	case ectologger.BoolType:
+		return zap.Bool(f.Key, f.Integer == 1)
+	case ectologger.DurationType:
+		return zap.Duration(f.Key, time.Duration(f.Integer))
+	case ectologger.TimeType:
+		if t, ok := f.Interface.(time.Time); ok {
+			return zap.Time(f.Key, t)
+		}
+		return zap.Any(f.Key, f.Interface)
+	case ectologger.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return zap.NamedError(f.Key, err)
+		}
+		return zap.Skip()
+	case ectologger.ObjectType:
+		fields, _ := f.Interface.([]ectologger.Field)
+		return zap.Object(f.Key, objectMarshaler(fields))
+	case ectologger.ArrayType:
+		values, _ := f.Interface.([]interface{})
+		return zap.Array(f.Key, arrayMarshaler(values))
+	case ectologger.NamespaceType:
+		return zap.Namespace(f.Key)
+	case ectologger.SkipType:
+		return zap.Skip()
+	default:
+		return zap.Any(f.Key, f.Interface)
+	}
+}
+
+// objectMarshaler writes nested typed fields as a zap object
+type objectMarshaler []ectologger.Field
+
+func (o objectMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range o {
+		toZapField(f).AddTo(enc)
+	}
+	return nil
+}
+
+// arrayMarshaler writes array values as a zap array, only falling back to reflection for unknown types
+type arrayMarshaler []interface{}
+
+func (a arrayMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, v := range a {
+		switch v := v.(type) {
+		case string:
+			enc.AppendString(v)
+		case int:
+			enc.AppendInt(v)
+		case int64:
+			enc.AppendInt64(v)
+		case float64:
+			enc.AppendFloat64(v)
+		case bool:
+			enc.AppendBool(v)
+		case time.Duration:
+			enc.AppendDuration(v)
+		case time.Time:
+			enc.AppendTime(v)
+		case error:
+			enc.AppendString(v.Error())
+		case ectologger.Field:
+			if err := enc.AppendObject(objectMarshaler{v}); err != nil {
+				return err
+			}
+		default:
+			if err := enc.AppendReflected(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // GetZapLogFunc returns a log function that logs to the provided zap logger
 // before is a function that is called before the log message is logged.
 // It can be used to modify the log message or add additional fields to it.
+// For more than one step, pass nil and chain ectologger processors in front of the returned function.
 func GetZapLogFunc(zapLogger *zap.Logger, before func(msg ectologger.EctoLogMessage) ectologger.EctoLogMessage) ectologger.EctoLogFunc {
-	return func(msg ectologger.EctoLogMessage) {
-		if before != nil {
-			msg = before(msg)
-		}
-
-		zapFields := fieldsToZapFields(msg.Fields)
+	logFunc := func(msg ectologger.EctoLogMessage) {
 		level, err := zapcore.ParseLevel(msg.Level)
-		if err != nil {
+		if msg.Level == ectologger.TraceLevel {
+			level = zapcore.DebugLevel // zap has no level below Debug
+		} else if err != nil {
 			level = zapcore.InfoLevel // Default to Info level if parsing fails
 		}
 
+		// Check before building fields so LogValuers only run when zap will write the entry
+		ce := zapLogger.Check(level, msg.Message)
+		if ce == nil {
+			return
+		}
+		msg = msg.Resolve()
+		if !msg.Time.IsZero() {
+			ce.Time = msg.Time // Keep the time of the call site rather than of the write
+		}
+
+		zapFields := fieldsToZapFields(msg.Fields)
+
 		if msg.Err != nil {
 			zapFields = append(zapFields, zap.Error(msg.Err))
 		}
 
-		zapLogger.Log(level, msg.Message, zapFields...)
+		// Typed fields go last so a namespace among them only nests the fields that follow it
+		if len(msg.TypedFields) > 0 {
+			zapFields = append(zapFields, typedFieldsToZapFields(msg.TypedFields)...)
+		}
+
+		ce.Write(zapFields...)
+	}
+
+	if before == nil {
+		return logFunc
 	}
+	return ectologger.Chain(logFunc, ectologger.Mutate(before))
 }
 
 // NewZapEctoLogger returns a new EctoLogger that logs to the provided zap logger
 // before is an optional function that is called before the log message is logged.
 // It can be used to modify the log message or add additional fields to it.
-func NewZapEctoLogger(zapLogger *zap.Logger, before func(msg ectologger.EctoLogMessage) ectologger.EctoLogMessage) ectologger.Logger {
-	return ectologger.NewEctoLogger(GetZapLogFunc(zapLogger, before))
+// opts configure the logger, e.g. ectologger.WithProcessors to run a processor chain.
+func NewZapEctoLogger(zapLogger *zap.Logger, before func(msg ectologger.EctoLogMessage) ectologger.EctoLogMessage, opts ...ectologger.Option) ectologger.Logger {
+	return ectologger.NewEctoLogger(GetZapLogFunc(zapLogger, before), opts...)
 }
@@ -0,0 +1,115 @@
+package zapadapter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/Gobusters/ectologger/ectologgertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZapEctoLoggerTypedFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := NewZapEctoLogger(zap.New(core), nil)
+
+	logger.WithTypedFields(
+		ectologger.String("string", "value"),
+		ectologger.Int64("int", 1),
+		ectologger.Float64("float", 1.5),
+		ectologger.Bool("bool", true),
+		ectologger.Duration("duration", time.Second),
+		ectologger.Err(errors.New("test error")),
+		ectologger.Object("object", ectologger.String("nested", "value")),
+		ectologger.Array("array", "a", 1),
+		ectologger.Namespace("ns"),
+		ectologger.String("inner", "value"),
+	).Warn("test message")
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.WarnLevel, entry.Level)
+	assert.Equal(t, "test message", entry.Message)
+
+	fields := entry.ContextMap()
+	assert.Equal(t, "value", fields["string"])
+	assert.Equal(t, int64(1), fields["int"])
+	assert.Equal(t, 1.5, fields["float"])
+	assert.Equal(t, true, fields["bool"])
+	assert.Equal(t, time.Second, fields["duration"])
+	assert.Equal(t, "test error", fields["error"])
+	assert.Equal(t, map[string]interface{}{"nested": "value"}, fields["object"])
+	assert.Equal(t, []interface{}{"a", 1}, fields["array"])
+	assert.Equal(t, map[string]interface{}{"inner": "value"}, fields["ns"])
+}
+
+func TestZapEctoLoggerMapFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := NewZapEctoLogger(zap.New(core), nil)
+
+	logger.WithField("key", "value").Info("test message")
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]interface{}{"key": "value"}, logs.All()[0].ContextMap())
+}
+
+type countingValuer struct {
+	calls int
+}
+
+func (c *countingValuer) LogValue() interface{} {
+	c.calls++
+	return "resolved"
+}
+
+func TestZapEctoLoggerResolvesLogValuersOnce(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := NewZapEctoLogger(zap.New(core), nil)
+	valuer := &countingValuer{}
+
+	logger.WithField("lazy", valuer).Debug("filtered by zap")
+	assert.Equal(t, 0, valuer.calls)
+
+	logger.WithTypedFields(ectologger.Any("lazy", valuer)).Info("test message")
+	assert.Equal(t, 1, valuer.calls)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "resolved", logs.All()[0].ContextMap()["lazy"])
+}
+
+func TestZapEctoLoggerWithGroup(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := NewZapEctoLogger(zap.New(core), nil)
+
+	logger.WithField("id", "root").WithGroup("db").WithField("id", "db").Info("test message")
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]interface{}{
+		"id": "root",
+		"db": map[string]interface{}{"id": "db"},
+	}, logs.All()[0].ContextMap())
+}
+
+func TestZapLogFuncMapsTraceToDebug(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	GetZapLogFunc(zap.New(core), nil)(ectologger.EctoLogMessage{Level: ectologger.TraceLevel, Message: "test message"})
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, zapcore.DebugLevel, logs.All()[0].Level)
+}
+
+func TestZapEctoLoggerKeepsCallSiteTime(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	clock := ectologgertest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := NewZapEctoLogger(zap.New(core), nil, ectologger.WithClock(clock))
+
+	logger.Info("test message")
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, clock.Now(), logs.All()[0].Time)
+}
@@ -0,0 +1,205 @@
+package zerologadapter
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/rs/zerolog"
+)
+
+// toZerologLevel maps an ectologger level to the zerolog level. Unknown levels map to Info.
+func toZerologLevel(level string) zerolog.Level {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil || level == "" {
+		return zerolog.InfoLevel // Default to Info level if parsing fails
+	}
+	return parsed
+}
+
+// newEvent starts an event at level. Fatal and Panic events are started through the
+// logger's Fatal and Panic methods so they exit or panic after being written, which
+// zerolog's WithLevel deliberately does not do.
+func newEvent(logger *zerolog.Logger, level zerolog.Level) *zerolog.Event {
+	switch level {
+	case zerolog.FatalLevel:
+		return logger.Fatal()
+	case zerolog.PanicLevel:
+		return logger.Panic()
+	default:
+		return logger.WithLevel(level)
+	}
+}
+
+// hooksField is the index of the unexported hooks field of zerolog.Logger, or -1 if this
+// zerolog version has none.
+var hooksField = func() int {
+	if field, ok := reflect.TypeOf(zerolog.Logger{}).FieldByName("hooks"); ok && len(field.Index) == 1 {
+		return field.Index[0]
+	}
+	return -1
+}()
+
+// hasTimestampHook reports whether the logger was created with .Timestamp(), which adds a hook
+// writing zerolog.TimestampFieldName to every event. The hook type is unexported, so it is
+// recognized by its name.
+func hasTimestampHook(logger *zerolog.Logger) bool {
+	if hooksField < 0 {
+		return false
+	}
+	hooks := reflect.ValueOf(logger).Elem().Field(hooksField)
+	if hooks.Kind() != reflect.Slice {
+		return false
+	}
+	for i := 0; i < hooks.Len(); i++ {
+		if hook := hooks.Index(i); hook.Kind() == reflect.Interface && !hook.IsNil() {
+			if t := hook.Elem().Type(); t.PkgPath() == "github.com/rs/zerolog" && t.Name() == "timestampHook" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetZerologLogFunc returns a log function that logs to the provided zerolog logger.
+// Fields are written with zerolog's typed methods instead of going through a map, the
+// message is dropped early when below the logger's or zerolog's global level, and a
+// logger attached to the message context with zerolog's WithContext takes precedence.
+//
+// The time the message was logged is written under zerolog.TimestampFieldName. Loggers created
+// with .Timestamp() write that field themselves, with the time zerolog.TimestampFunc returns
+// when the event is written, so the message time is skipped for them rather than written twice.
+func GetZerologLogFunc(zerologLogger *zerolog.Logger) ectologger.EctoLogFunc {
+	return func(msg ectologger.EctoLogMessage) {
+		logger := zerologLogger
+		if msg.Ctx != nil {
+			if ctxLogger := zerolog.Ctx(msg.Ctx); ctxLogger != zerolog.DefaultContextLogger && ctxLogger.GetLevel() != zerolog.Disabled {
+				logger = ctxLogger
+			}
+		}
+
+		// A nil event means the level is disabled, skip resolving LogValuers entirely
+		e := newEvent(logger, toZerologLevel(msg.Level))
+		if e == nil {
+			return
+		}
+		msg = msg.Resolve()
+
+		if !msg.Time.IsZero() && !hasTimestampHook(logger) {
+			e = e.Time(zerolog.TimestampFieldName, msg.Time)
+		}
+		if msg.Ctx != nil {
+			e = e.Ctx(msg.Ctx)
+		}
+		for k, v := range msg.Fields {
+			e = addValue(e, k, v)
+		}
+		if msg.Err != nil {
+			e = e.Err(msg.Err)
+		}
+		e = addFields(e, msg.TypedFields)
+
+		e.Msg(msg.Message)
+	}
+}
+
+// NewZerologEctoLogger returns a new EctoLogger that logs to the provided zerolog logger.
+func NewZerologEctoLogger(zerologLogger *zerolog.Logger, opts ...ectologger.Option) ectologger.Logger {
+	return ectologger.NewEctoLogger(GetZerologLogFunc(zerologLogger), opts...)
+}
+
+// addFields writes typed fields to e. The fields following a namespace are written into
+// a nested dictionary, as zerolog has no namespace concept of its own.
+func addFields(e *zerolog.Event, fields []ectologger.Field) *zerolog.Event {
+	for i, f := range fields {
+		switch f.Type {
+		case ectologger.StringType:
+			e = e.Str(f.Key, f.String)
+		case ectologger.Int64Type:
+			e = e.Int64(f.Key, f.Integer)
+		case ectologger.Float64Type:
+			e = e.Float64(f.Key, f.Float)
+		case ectologger.BoolType:
+			e = e.Bool(f.Key, f.Integer == 1)
+		case ectologger.DurationType:
+			e = e.Dur(f.Key, time.Duration(f.Integer))
+		case ectologger.TimeType, ectologger.ErrorType, ectologger.AnyType:
+			e = addValue(e, f.Key, f.Interface)
+		case ectologger.ObjectType:
+			nested, _ := f.Interface.([]ectologger.Field)
+			e = e.Dict(f.Key, addFields(zerolog.Dict(), nested))
+		case ectologger.ArrayType:
+			values, _ := f.Interface.([]interface{})
+			e = e.Array(f.Key, toArray(values))
+		case ectologger.NamespaceType:
+			return e.Dict(f.Key, addFields(zerolog.Dict(), fields[i+1:]))
+		}
+	}
+	return e
+}
+
+// addValue writes an untyped value to e, switching on its type so the common cases avoid reflection.
+func addValue(e *zerolog.Event, key string, value interface{}) *zerolog.Event {
+	switch v := value.(type) {
+	case string:
+		return e.Str(key, v)
+	case int:
+		return e.Int(key, v)
+	case int64:
+		return e.Int64(key, v)
+	case int32:
+		return e.Int32(key, v)
+	case uint:
+		return e.Uint(key, v)
+	case uint64:
+		return e.Uint64(key, v)
+	case float64:
+		return e.Float64(key, v)
+	case float32:
+		return e.Float32(key, v)
+	case bool:
+		return e.Bool(key, v)
+	case time.Duration:
+		return e.Dur(key, v)
+	case time.Time:
+		return e.Time(key, v)
+	case error:
+		return e.AnErr(key, v)
+	case []string:
+		return e.Strs(key, v)
+	case map[string]interface{}:
+		return e.Dict(key, zerolog.Dict().Fields(v))
+	case nil:
+		return e.Interface(key, nil)
+	default:
+		return e.Interface(key, v)
+	}
+}
+
+// toArray converts array field values to a zerolog array.
+func toArray(values []interface{}) *zerolog.Array {
+	arr := zerolog.Arr()
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			arr = arr.Str(v)
+		case int:
+			arr = arr.Int(v)
+		case int64:
+			arr = arr.Int64(v)
+		case float64:
+			arr = arr.Float64(v)
+		case bool:
+			arr = arr.Bool(v)
+		case time.Duration:
+			arr = arr.Dur(v)
+		case time.Time:
+			arr = arr.Time(v)
+		case error:
+			arr = arr.Err(v)
+		default:
+			arr = arr.Interface(v)
+		}
+	}
+	return arr
+}
@@ -0,0 +1,129 @@
+package zerologadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/Gobusters/ectologger/ectologgertest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	return out
+}
+
+func TestZerologEctoLogger(t *testing.T) {
+	var buf bytes.Buffer
+	zerologLogger := zerolog.New(&buf)
+	clock := ectologgertest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := NewZerologEctoLogger(&zerologLogger, ectologger.WithClock(clock))
+
+	logger.WithField("key", "value").
+		WithError(errors.New("test error")).
+		WithTypedFields(
+			ectologger.Int64("count", 2),
+			ectologger.Duration("elapsed", time.Second),
+			ectologger.Object("object", ectologger.Bool("ok", true)),
+			ectologger.Array("array", "a", 1),
+			ectologger.Namespace("ns"),
+			ectologger.String("inner", "value"),
+		).
+		Warn("test message")
+
+	assert.Equal(t, map[string]interface{}{
+		"level":   "warn",
+		"time":    "2024-01-01T00:00:00Z",
+		"message": "test message",
+		"key":     "value",
+		"error":   "test error",
+		"count":   float64(2),
+		"elapsed": float64(1000),
+		"object":  map[string]interface{}{"ok": true},
+		"array":   []interface{}{"a", float64(1)},
+		"ns":      map[string]interface{}{"inner": "value"},
+	}, decode(t, &buf))
+}
+
+type countingValuer struct {
+	calls int
+}
+
+func (c *countingValuer) LogValue() interface{} {
+	c.calls++
+	return "resolved"
+}
+
+func TestZerologEctoLoggerHonorsLevels(t *testing.T) {
+	var buf bytes.Buffer
+	zerologLogger := zerolog.New(&buf).Level(zerolog.InfoLevel)
+	logger := NewZerologEctoLogger(&zerologLogger)
+	valuer := &countingValuer{}
+
+	logger.WithField("lazy", valuer).Debug("filtered")
+	assert.Empty(t, buf.String())
+
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	logger.WithField("lazy", valuer).Info("filtered globally")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, 0, valuer.calls)
+}
+
+func TestZerologEctoLoggerUsesContextLogger(t *testing.T) {
+	var defaultBuf, ctxBuf bytes.Buffer
+	zerologLogger := zerolog.New(&defaultBuf)
+	ctxLogger := zerolog.New(&ctxBuf).With().Str("request_id", "123").Logger()
+	ctx := ctxLogger.WithContext(context.Background())
+
+	NewZerologEctoLogger(&zerologLogger).InfoContext(ctx, "test message")
+
+	assert.Empty(t, defaultBuf.String())
+	assert.Equal(t, "123", decode(t, &ctxBuf)["request_id"])
+}
+
+func TestZerologPanicLevel(t *testing.T) {
+	var buf bytes.Buffer
+	zerologLogger := zerolog.New(&buf)
+	logFunc := GetZerologLogFunc(&zerologLogger)
+
+	assert.Panics(t, func() {
+		logFunc(ectologger.EctoLogMessage{Level: "panic", Message: "test message"})
+	})
+	assert.Equal(t, "panic", decode(t, &buf)["level"])
+}
+
+func TestZerologEctoLoggerKeepsCallSiteTime(t *testing.T) {
+	var buf bytes.Buffer
+	zerologLogger := zerolog.New(&buf)
+	clock := ectologgertest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := NewZerologEctoLogger(&zerologLogger, ectologger.WithClock(clock))
+
+	logger.Info("test message")
+
+	assert.Equal(t, "2020-01-01T00:00:00Z", decode(t, &buf)[zerolog.TimestampFieldName])
+}
+
+func TestZerologEctoLoggerWritesTimestampOnce(t *testing.T) {
+	var buf bytes.Buffer
+	zerologLogger := zerolog.New(&buf).With().Timestamp().Logger()
+	clock := ectologgertest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	logger := NewZerologEctoLogger(&zerologLogger, ectologger.WithClock(clock))
+
+	logger.Info("test message")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), `"`+zerolog.TimestampFieldName+`":`))
+	assert.True(t, hasTimestampHook(&zerologLogger))
+	plain := zerolog.New(&buf).Hook(zerolog.HookFunc(func(*zerolog.Event, zerolog.Level, string) {}))
+	assert.False(t, hasTimestampHook(&plain))
+}
@@ -0,0 +1,85 @@
+package zerologadapter
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectologger"
+	"github.com/Gobusters/ectologger/zapadapter"
+	"github.com/rs/zerolog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newBenchZapLogger() *zap.Logger {
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return zap.New(zapcore.NewCore(encoder, zapcore.AddSync(io.Discard), zapcore.DebugLevel))
+}
+
+func newBenchZerologLogger() *zerolog.Logger {
+	logger := zerolog.New(io.Discard).With().Timestamp().Logger()
+	return &logger
+}
+
+func benchmarkTypedFields(b *testing.B, logger ectologger.Logger) {
+	logger = logger.WithTypedFields(
+		ectologger.String("request_id", "12345"),
+		ectologger.Int64("status", 200),
+		ectologger.Duration("elapsed", 15*time.Millisecond),
+		ectologger.Bool("cached", true),
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("request handled")
+	}
+}
+
+func benchmarkMapFields(b *testing.B, logger ectologger.Logger) {
+	logger = logger.WithFields(map[string]interface{}{
+		"request_id": "12345",
+		"status":     200,
+		"elapsed":    15 * time.Millisecond,
+		"cached":     true,
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("request handled")
+	}
+}
+
+func BenchmarkZerologTypedFields(b *testing.B) {
+	benchmarkTypedFields(b, NewZerologEctoLogger(newBenchZerologLogger()))
+}
+
+func BenchmarkZapTypedFields(b *testing.B) {
+	benchmarkTypedFields(b, zapadapter.NewZapEctoLogger(newBenchZapLogger(), nil))
+}
+
+func BenchmarkZerologMapFields(b *testing.B) {
+	benchmarkMapFields(b, NewZerologEctoLogger(newBenchZerologLogger()))
+}
+
+func BenchmarkZapMapFields(b *testing.B) {
+	benchmarkMapFields(b, zapadapter.NewZapEctoLogger(newBenchZapLogger(), nil))
+}
+
+func BenchmarkZerologDisabled(b *testing.B) {
+	logger := newBenchZerologLogger().Level(zerolog.InfoLevel)
+	benchmarkDisabled(b, NewZerologEctoLogger(&logger))
+}
+
+func BenchmarkZapDisabled(b *testing.B) {
+	benchmarkDisabled(b, zapadapter.NewZapEctoLogger(newBenchZapLogger().WithOptions(zap.IncreaseLevel(zapcore.InfoLevel)), nil))
+}
+
+func benchmarkDisabled(b *testing.B, logger ectologger.Logger) {
+	logger = logger.WithTypedFields(ectologger.String("request_id", "12345"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Debug("request handled")
+	}
+}